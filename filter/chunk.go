@@ -0,0 +1,41 @@
+package filter
+
+import (
+	"reflect"
+)
+
+// Chunk an array of T into [][]T, each with at most size entries
+// The last chunk holds the remainder, and empty source results in empty [][]T
+func Chunk(source interface{}, size int) (interface{}, error) {
+	srcV := reflect.ValueOf(source)
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, ErrSourceNotArray
+	}
+
+	if size <= 0 {
+		return nil, ErrChunkSize
+	}
+
+	T := reflect.TypeOf(source).Elem()           // 1. Get type T of source's element
+	sliceOfT := reflect.SliceOf(T)               // 2. Get type []T
+	sliceOfSliceOfT := reflect.SliceOf(sliceOfT) // 3. Get type [][]T
+
+	n := srcV.Len()
+	chunks := reflect.MakeSlice(sliceOfSliceOfT, 0, (n+size-1)/size)
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+
+		// copy entries into a new chunk, so it does not share memory with source
+		chunk := reflect.MakeSlice(sliceOfT, end-start, end-start)
+		for i := start; i < end; i++ {
+			chunk.Index(i - start).Set(srcV.Index(i))
+		}
+		chunks = reflect.Append(chunks, chunk)
+	}
+
+	return chunks.Interface(), nil
+}
@@ -0,0 +1,52 @@
+package filter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bastianrob/go-experiences/filter"
+)
+
+func TestChunk(t *testing.T) {
+	type args struct {
+		arr  interface{}
+		size int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		want    interface{}
+	}{
+		{"Exact", args{
+			arr:  []int{1, 2, 3, 4},
+			size: 2}, false, [][]int{{1, 2}, {3, 4}}},
+		{"Remainder", args{
+			arr:  [5]string{"a", "b", "c", "d", "e"},
+			size: 2}, false, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}},
+		{"Bigger than source", args{
+			arr:  []int{1, 2},
+			size: 10}, false, [][]int{{1, 2}}},
+		{"Empty source", args{
+			arr:  []int{},
+			size: 2}, false, [][]int{}},
+		{"Failed", args{
+			arr:  "[]int{1, 2, 3, 4}",
+			size: 2}, true, nil},
+		{"Failed", args{
+			arr:  []int{1, 2, 3, 4},
+			size: 0}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filter.Chunk(tt.args.arr, tt.args.size)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Chunk() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Chunk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
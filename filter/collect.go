@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"reflect"
+)
+
+// CollectTo drains a channel, e.g. of DeferredFilter, into dest until the channel is closed
+// dest must be a pointer to a slice, on which every received entry is appended
+func CollectTo(ch <-chan interface{}, dest interface{}) error {
+	destV := reflect.ValueOf(dest)
+	if destV.Kind() != reflect.Ptr || destV.Elem().Kind() != reflect.Slice {
+		return ErrDestNotSlicePtr
+	}
+
+	sliceV := destV.Elem()
+	elemT := sliceV.Type().Elem()
+
+	var err error
+	for entry := range ch {
+		// keep draining after an error, so the sender never blocks
+		if err != nil {
+			continue
+		}
+
+		entryV := reflect.ValueOf(entry)
+		if !entryV.IsValid() {
+			entryV = reflect.Zero(elemT) // nil entry is appended as zero value
+		}
+		if !entryV.Type().AssignableTo(elemT) {
+			err = ErrEntryNotAssignable
+			continue
+		}
+
+		sliceV = reflect.Append(sliceV, entryV)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	destV.Elem().Set(sliceV)
+	return nil
+}
@@ -0,0 +1,64 @@
+package filter_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/bastianrob/go-experiences/filter"
+)
+
+func TestCollectTo(t *testing.T) {
+	isMultipliedBy3 := func(num int) bool {
+		return num%3 == 0
+	}
+
+	q, err := filter.DeferredFilter([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}, isMultipliedBy3)
+	if err != nil {
+		t.Fatalf("DeferredFilter() error = %v", err)
+	}
+
+	var got []int
+	if err := filter.CollectTo(q, &got); err != nil {
+		t.Errorf("CollectTo() error = %v, wantErr %v", err, false)
+		return
+	}
+
+	// deferred filter does not guarantee order
+	sort.Ints(got)
+	if want := []int{3, 6, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectTo() = %v, want %v", got, want)
+	}
+}
+
+func TestCollectToErrors(t *testing.T) {
+	closed := func(entries ...interface{}) <-chan interface{} {
+		ch := make(chan interface{}, len(entries))
+		for _, entry := range entries {
+			ch <- entry
+		}
+		close(ch)
+		return ch
+	}
+
+	type args struct {
+		ch   <-chan interface{}
+		dest interface{}
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr error
+	}{
+		{"Destination is not a pointer", args{closed(1), []int{}}, filter.ErrDestNotSlicePtr},
+		{"Destination is not a slice", args{closed(1), new(int)}, filter.ErrDestNotSlicePtr},
+		{"Entry is not assignable", args{closed(1, "two", 3), &[]int{}}, filter.ErrEntryNotAssignable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := filter.CollectTo(tt.args.ch, tt.args.dest); err != tt.wantErr {
+				t.Errorf("CollectTo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -43,7 +43,7 @@ func DeferredFilter(source, filter interface{}) (<-chan interface{}, error) {
 			// if result is valid, send the entry into queue
 			// else, send zero value into queue
 			if valid {
-				queue <- &entry
+				queue <- entry.Interface()
 			}
 		}(i, srcV.Index(i))
 	}
@@ -0,0 +1,80 @@
+package filter
+
+import (
+	"reflect"
+)
+
+// Distinct entries of an array of T, preserving the first seen order
+// Entries are compared by value, so an array of pointers is deduped by pointer identity
+// Use DistinctBy to dedupe by the pointed value instead
+func Distinct(source interface{}) (interface{}, error) {
+	srcV := reflect.ValueOf(source)
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, ErrSourceNotArray
+	}
+
+	return distinct(srcV, func(entry reflect.Value) reflect.Value {
+		return entry
+	})
+}
+
+// DistinctBy entries of an array of T by its key, preserving the first seen order
+// keyFunc is a func(T) K, where K must be comparable
+func DistinctBy(source, keyFunc interface{}) (interface{}, error) {
+	srcV := reflect.ValueOf(source)
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, ErrSourceNotArray
+	}
+
+	if keyFunc == nil {
+		return nil, ErrFilterFuncNil
+	}
+
+	kv := reflect.ValueOf(keyFunc)
+	if kv.Kind() != reflect.Func {
+		return nil, ErrFilterNotFunc
+	}
+
+	if kv.Type().NumOut() != 1 || !kv.Type().Out(0).Comparable() {
+		return nil, ErrNotComparable
+	}
+
+	return distinct(srcV, func(entry reflect.Value) reflect.Value {
+		return kv.Call([]reflect.Value{entry})[0]
+	})
+}
+
+// distinct entries of source by the key of each entry
+func distinct(srcV reflect.Value, key func(entry reflect.Value) reflect.Value) (interface{}, error) {
+	T := srcV.Type().Elem() // Get type T of source's element
+	result := reflect.MakeSlice(reflect.SliceOf(T), 0, srcV.Len())
+
+	seen := map[interface{}]struct{}{}
+	for i := 0; i < srcV.Len(); i++ {
+		entry := srcV.Index(i)
+		k := key(entry)
+		if !isComparable(k) {
+			return nil, ErrNotComparable
+		}
+
+		if _, exists := seen[k.Interface()]; exists {
+			continue
+		}
+
+		seen[k.Interface()] = struct{}{}
+		result = reflect.Append(result, entry)
+	}
+
+	return result.Interface(), nil
+}
+
+// isComparable check the dynamic type of k, as an interface{} key is always comparable by its static type
+func isComparable(k reflect.Value) bool {
+	if k.Kind() == reflect.Interface && !k.IsNil() {
+		return k.Elem().Type().Comparable()
+	}
+
+	return k.Type().Comparable()
+}
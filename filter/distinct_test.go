@@ -0,0 +1,104 @@
+package filter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bastianrob/go-experiences/filter"
+)
+
+func TestDistinct(t *testing.T) {
+	type Person struct {
+		Name       string
+		Birthplace string
+	}
+	tests := []struct {
+		name    string
+		arr     interface{}
+		wantErr bool
+		want    interface{}
+	}{
+		{"Ints", []int{3, 1, 3, 2, 1}, false, []int{3, 1, 2}},
+		{"Strings", [4]string{"b", "a", "b", "b"}, false, []string{"b", "a"}},
+		{"Structs", []Person{
+			{"Robin", "Gotham"},
+			{"Robin", "Bludhaven"},
+			{"Robin", "Gotham"},
+		}, false, []Person{
+			{"Robin", "Gotham"},
+			{"Robin", "Bludhaven"},
+		}},
+		{"Empty", []int{}, false, []int{}},
+		{"Failed", "[]int{1, 2, 3, 4}", true, nil},
+		{"Failed", [][]int{{1}, {1}}, true, nil},
+		{"Failed", []interface{}{[]int{1}}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filter.Distinct(tt.arr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Distinct() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Distinct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistinctBy(t *testing.T) {
+	intptr := func(num int) *int {
+		return &num
+	}
+	one, two := intptr(1), intptr(2)
+	type args struct {
+		arr     interface{}
+		keyFunc interface{}
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		want    interface{}
+	}{
+		{"Pointer value", args{
+			arr: []*int{one, intptr(1), two},
+			keyFunc: func(entry *int) int {
+				return *entry
+			}}, false, []*int{one, two}},
+		{"First letter", args{
+			arr: []string{"apple", "avocado", "banana"},
+			keyFunc: func(entry string) byte {
+				return entry[0]
+			}}, false, []string{"apple", "banana"}},
+		{"Failed", args{
+			arr:     []int{1, 2},
+			keyFunc: nil}, true, nil},
+		{"Failed", args{
+			arr:     []int{1, 2},
+			keyFunc: "func"}, true, nil},
+		{"Failed", args{
+			arr: []int{1, 2},
+			keyFunc: func(entry int) []int {
+				return []int{entry}
+			}}, true, nil},
+		{"Failed", args{
+			arr: []int{1, 2},
+			keyFunc: func(entry int) interface{} {
+				return map[int]bool{entry: true}
+			}}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filter.DistinctBy(tt.args.arr, tt.args.keyFunc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DistinctBy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DistinctBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
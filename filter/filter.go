@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"context"
 	"reflect"
 )
 
@@ -45,3 +46,53 @@ func Filter(source, filter interface{}) (interface{}, error) {
 
 	return ptrToElementOfSliceT.Interface(), nil
 }
+
+// contextType is the type of context.Context, the first parameter of FilterCtx's filter
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// FilterCtx an array without go routine, passing ctx into each filter call
+// filter is a func(context.Context, T) bool, e.g: a predicate doing I/O
+// ctx is checked between entries, and the filtering aborts with ctx error once it is done
+func FilterCtx(ctx context.Context, source, filter interface{}) (interface{}, error) {
+	srcV := reflect.ValueOf(source)
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, ErrSourceNotArray
+	}
+
+	if filter == nil {
+		return nil, ErrFilterFuncNil
+	}
+
+	T := reflect.TypeOf(source).Elem()
+	fv := reflect.ValueOf(filter)
+	if fv.Kind() != reflect.Func {
+		return nil, ErrFilterNotFunc
+	}
+
+	ft := fv.Type()
+	if ft.NumIn() != 2 || ft.In(0) != contextType || !T.AssignableTo(ft.In(1)) ||
+		ft.NumOut() != 1 || ft.Out(0).Kind() != reflect.Bool {
+		return nil, ErrFilterNotFunc
+	}
+
+	result := reflect.MakeSlice(reflect.SliceOf(T), 0, 0)
+	ctxV := reflect.ValueOf(&ctx).Elem() // keeps ctx typed as context.Context
+
+	for i := 0; i < srcV.Len(); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		entry := srcV.Index(i)
+		valid := fv.
+			Call([]reflect.Value{ctxV, entry})[0].
+			Bool()
+
+		if valid {
+			result = reflect.Append(result, entry)
+		}
+	}
+
+	return result.Interface(), nil
+}
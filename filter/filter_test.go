@@ -1,6 +1,7 @@
 package filter_test
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -49,6 +50,84 @@ func TestFilter(t *testing.T) {
 	}
 }
 
+func TestFilterCtx(t *testing.T) {
+	type args struct {
+		arr     interface{}
+		filterf interface{}
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		want    interface{}
+	}{
+		{"Success", args{
+			arr: []int{1, 2, 3, 4},
+			filterf: func(ctx context.Context, entry int) bool {
+				return entry%2 == 0
+			}}, false, []int{2, 4}},
+		{"Failed", args{
+			arr: []int{1, 2, 3, 4},
+			filterf: func(entry int) bool {
+				return entry%2 == 0
+			}}, true, nil},
+		{"Failed", args{
+			arr:     "[]int{1, 2, 3, 4}",
+			filterf: nil}, true, nil},
+		{"Failed", args{
+			arr: []int{1, 2, 3, 4},
+			filterf: func(first, entry int) bool {
+				return entry%2 == 0
+			}}, true, nil},
+		{"Failed", args{
+			arr: []int{1, 2, 3, 4},
+			filterf: func(ctx context.Context, entry string) bool {
+				return entry != ""
+			}}, true, nil},
+		{"Failed", args{
+			arr: []int{1, 2, 3, 4},
+			filterf: func(ctx context.Context, entry int) int {
+				return entry % 2
+			}}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filter.FilterCtx(context.Background(), tt.args.arr, tt.args.filterf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FilterCtx() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterCtx() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterCtx_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	called := 0
+	got, err := filter.FilterCtx(ctx, []int{1, 2, 3, 4, 5}, func(ctx context.Context, entry int) bool {
+		called++
+		if entry == 2 {
+			cancel() // e.g: caller gave up while the predicate is doing I/O
+		}
+		return true
+	})
+
+	if err != context.Canceled {
+		t.Errorf("FilterCtx() error = %v, want %v", err, context.Canceled)
+	}
+	if got != nil {
+		t.Errorf("FilterCtx() = %v, want nil", got)
+	}
+	if called != 2 {
+		t.Errorf("FilterCtx() should stop calling filter once cancelled, called %v times", called)
+	}
+}
+
 func BenchmarkFilterFast(b *testing.B) {
 	source := [100]int{}
 	for i := 0; i < len(source); i++ {
@@ -0,0 +1,37 @@
+package filter
+
+import (
+	"reflect"
+)
+
+// FlatMap an array of T into []U
+// mapper is a func(T) []U, and all of its results are concatenated in order
+func FlatMap(source, mapper interface{}) (interface{}, error) {
+	srcV := reflect.ValueOf(source)
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, ErrSourceNotArray
+	}
+
+	if mapper == nil {
+		return nil, ErrFilterFuncNil
+	}
+
+	mv := reflect.ValueOf(mapper)
+	if mv.Kind() != reflect.Func {
+		return nil, ErrFilterNotFunc
+	}
+
+	if mv.Type().NumOut() != 1 || mv.Type().Out(0).Kind() != reflect.Slice {
+		return nil, ErrMapperNotSlice
+	}
+
+	sliceOfU := mv.Type().Out(0) // Get type []U of mapper's result
+	result := reflect.MakeSlice(sliceOfU, 0, srcV.Len())
+	for i := 0; i < srcV.Len(); i++ {
+		mapped := mv.Call([]reflect.Value{srcV.Index(i)})[0]
+		result = reflect.AppendSlice(result, mapped)
+	}
+
+	return result.Interface(), nil
+}
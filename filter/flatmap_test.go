@@ -0,0 +1,73 @@
+package filter_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bastianrob/go-experiences/filter"
+)
+
+func TestFlatMap(t *testing.T) {
+	type LineItem struct {
+		ID  string
+		Qty int
+	}
+	type Order struct {
+		ID    string
+		Items []LineItem
+	}
+	type args struct {
+		arr     interface{}
+		mapperf interface{}
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		want    interface{}
+	}{
+		{"Order into line items", args{
+			arr: []Order{
+				{ID: "ORD-001", Items: []LineItem{{"ITEM-001", 1}, {"ITEM-002", 2}}},
+				{ID: "ORD-002", Items: nil},
+				{ID: "ORD-003", Items: []LineItem{{"ITEM-003", 3}}},
+			},
+			mapperf: func(entry Order) []LineItem {
+				return entry.Items
+			}}, false, []LineItem{{"ITEM-001", 1}, {"ITEM-002", 2}, {"ITEM-003", 3}}},
+		{"Empty inner slices", args{
+			arr: []string{"", ""},
+			mapperf: func(entry string) []string {
+				return strings.Fields(entry)
+			}}, false, []string{}},
+		{"Words", args{
+			arr: [2]string{"I AM", "VENGEANCE"},
+			mapperf: func(entry string) []string {
+				return strings.Fields(entry)
+			}}, false, []string{"I", "AM", "VENGEANCE"}},
+		{"Failed", args{
+			arr: []int{1, 2},
+			mapperf: func(entry int) int {
+				return entry
+			}}, true, nil},
+		{"Failed", args{
+			arr:     "[]int{1, 2, 3, 4}",
+			mapperf: nil}, true, nil},
+		{"Failed", args{
+			arr:     []int{1, 2},
+			mapperf: nil}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filter.FlatMap(tt.args.arr, tt.args.mapperf)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FlatMap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FlatMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package filter
+
+import (
+	"reflect"
+)
+
+// ForEach entry of an array, without building any result
+// fn is a func(index int, entry T) bool, returning false stops the iteration, like a break
+func ForEach(source, fn interface{}) error {
+	srcV := reflect.ValueOf(source)
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return ErrSourceNotArray
+	}
+
+	if fn == nil {
+		return ErrFilterFuncNil
+	}
+
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func {
+		return ErrFilterNotFunc
+	}
+
+	for i := 0; i < srcV.Len(); i++ {
+		next := fv.
+			Call([]reflect.Value{reflect.ValueOf(i), srcV.Index(i)})[0].
+			Interface().(bool)
+
+		if !next {
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package filter_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bastianrob/go-experiences/filter"
+)
+
+func TestForEach(t *testing.T) {
+	source := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	var visited []int
+	err := filter.ForEach(source, func(idx int, entry int) bool {
+		visited = append(visited, entry)
+		return idx < 2
+	})
+	if err != nil {
+		t.Fatalf("ForEach() error = %v", err)
+	}
+	if !reflect.DeepEqual(visited, []int{1, 2, 3}) {
+		t.Errorf("ForEach() visited = %v, want iteration to stop at index 2", visited)
+	}
+
+	sum := 0
+	filter.ForEach([3]int{1, 2, 3}, func(idx int, entry int) bool {
+		sum += entry
+		return true
+	})
+	if sum != 6 {
+		t.Errorf("ForEach() sum = %v, want 6", sum)
+	}
+}
+
+func TestForEachFailed(t *testing.T) {
+	tests := []struct {
+		name   string
+		source interface{}
+		fn     interface{}
+		want   error
+	}{
+		{"Source must be an array", "[]int{1, 2, 3, 4}", func(int, int) bool { return true }, filter.ErrSourceNotArray},
+		{"Function must not be nil", []int{1}, nil, filter.ErrFilterFuncNil},
+		{"Function must be a function", []int{1}, "func", filter.ErrFilterNotFunc},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := filter.ForEach(tt.source, tt.fn); err != tt.want {
+				t.Errorf("ForEach() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
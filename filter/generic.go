@@ -0,0 +1,24 @@
+package filter
+
+// FilterG an array of T without go routine, type checked at compile time
+// Unlike Filter, it does not use reflection nor box entries into interface{}
+func FilterG[T any](source []T, pred func(T) bool) []T {
+	result := make([]T, 0)
+	for _, entry := range source {
+		if pred(entry) {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// MapG an array of T into []U, type checked at compile time
+func MapG[T, U any](source []T, fn func(T) U) []U {
+	result := make([]U, len(source))
+	for i, entry := range source {
+		result[i] = fn(entry)
+	}
+
+	return result
+}
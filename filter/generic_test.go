@@ -0,0 +1,71 @@
+package filter_test
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/bastianrob/go-experiences/filter"
+)
+
+func TestFilterG(t *testing.T) {
+	intptr := func(num int) *int {
+		return &num
+	}
+
+	if got, want := filter.FilterG([]int{1, 2, 3, 4}, func(entry int) bool {
+		return entry == 1
+	}), []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterG() = %v, want %v", got, want)
+	}
+
+	if got, want := filter.FilterG([]*int{intptr(1), intptr(2), intptr(3), intptr(4)}, func(entry *int) bool {
+		return *entry == 1
+	}), []*int{intptr(1)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterG() = %v, want %v", got, want)
+	}
+
+	if got := filter.FilterG(nil, func(entry int) bool { return true }); got == nil || len(got) != 0 {
+		t.Errorf("FilterG() = %v, want %v", got, []int{})
+	}
+}
+
+func TestMapG(t *testing.T) {
+	got := filter.MapG([]int{1, 2, 3}, strconv.Itoa)
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MapG() = %v, want %v", got, want)
+	}
+}
+
+func benchmarkSource() []int {
+	source := make([]int, 100000)
+	for i := 0; i < len(source); i++ {
+		source[i] = i + 1
+	}
+
+	return source
+}
+
+func BenchmarkFilterG(b *testing.B) {
+	source := benchmarkSource()
+	isMultipliedBy3 := func(num int) bool {
+		return num%3 == 0
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		filter.FilterG(source, isMultipliedBy3)
+	}
+}
+
+func BenchmarkFilterReflect(b *testing.B) {
+	source := benchmarkSource()
+	isMultipliedBy3 := func(num int) bool {
+		return num%3 == 0
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		filter.Filter(source, isMultipliedBy3)
+	}
+}
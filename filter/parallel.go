@@ -2,33 +2,60 @@ package filter
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"sync"
+	"time"
 )
 
 // Filter error collection
 var (
-	ErrSourceNotArray = errors.New("Source value is not an array")
-	ErrFilterFuncNil  = errors.New("Filter function cannot be nil")
-	ErrFilterNotFunc  = errors.New("Filter argument must be a function")
+	ErrSourceNotArray     = errors.New("Source value is not an array")
+	ErrFilterFuncNil      = errors.New("Filter function cannot be nil")
+	ErrFilterNotFunc      = errors.New("Filter argument must be a function")
+	ErrChunkSize          = errors.New("Chunk size must be greater than 0")
+	ErrNotComparable      = errors.New("Distinct key must be comparable")
+	ErrMapperNotSlice     = errors.New("Mapper function must return a slice")
+	ErrDestNotSlicePtr    = errors.New("Destination must be a pointer to a slice")
+	ErrEntryNotAssignable = errors.New("Entry is not assignable to destination slice")
+	ErrFilterPanic        = errors.New("Filter function panicked")
 )
 
+// FilterStats of a parallel filter run
+type FilterStats struct {
+	Processed  int           // number of elements processed
+	Matched    int           // number of elements which passes the filter
+	Goroutines int           // number of go routine spawned
+	Duration   time.Duration // wall-clock duration
+}
+
 // ParallelFilter an array using go routine
 // This function will not guarantee order of results
+// A panicking filter does not crash the program, the first panic is returned as ErrFilterPanic
 func ParallelFilter(source, filter interface{}) (interface{}, error) {
+	result, _, err := FilterWithStats(source, filter)
+	return result, err
+}
+
+// FilterWithStats is ParallelFilter which also reports its FilterStats
+// Useful to decide between Filter and ParallelFilter at runtime
+func FilterWithStats(source, filter interface{}) (interface{}, FilterStats, error) {
+	start := time.Now()
+	stats := FilterStats{}
+
 	srcV := reflect.ValueOf(source)
 	kind := srcV.Kind()
 	if kind != reflect.Slice && kind != reflect.Array {
-		return nil, ErrSourceNotArray
+		return nil, stats, ErrSourceNotArray
 	}
 
 	if filter == nil {
-		return nil, ErrFilterFuncNil
+		return nil, stats, ErrFilterFuncNil
 	}
 
 	fv := reflect.ValueOf(filter)
 	if fv.Kind() != reflect.Func {
-		return nil, ErrFilterNotFunc
+		return nil, stats, ErrFilterNotFunc
 	}
 
 	T := reflect.TypeOf(source).Elem()                      // 1. Get type T of source's element
@@ -50,31 +77,52 @@ func ParallelFilter(source, filter interface{}) (interface{}, error) {
 			if entry != nil {
 				appendResult := reflect.Append(ptrToElementOfSliceT, *entry)
 				ptrToElementOfSliceT.Set(appendResult)
+				stats.Matched++
 			}
 			wg.Done()
 		}
 	}()
 
+	// the first panic of filter function, if any
+	var panicked error
+	once := sync.Once{}
+
 	// for each entry in source
 	for i := 0; i < srcV.Len(); i++ {
 		// asynchronously check each entry
 		go func(idx int, entry reflect.Value) {
+			valid := false
+
+			// if result is valid, send the entry into queue
+			// else, send zero value into queue, even when filter function panics
+			defer func() {
+				if r := recover(); r != nil {
+					once.Do(func() { panicked = fmt.Errorf("%w: %v", ErrFilterPanic, r) })
+				}
+
+				if valid {
+					queue <- &entry
+				} else {
+					queue <- nil
+				}
+			}()
+
 			// call filter function via reflection, and check the result
-			valid := fv.
+			valid = fv.
 				Call([]reflect.Value{entry})[0].
 				Interface().(bool)
-
-			// if result is valid, send the entry into queue
-			// else, send zero value into queue
-			if valid {
-				queue <- &entry
-			} else {
-				queue <- nil
-			}
 		}(i, srcV.Index(i))
 	}
 
 	wg.Wait()    // wait for all filter to be done, and results appended to sliceValuePtr
 	close(queue) // close the queue channel so queue processor goroutine can exit
-	return ptrToElementOfSliceT.Interface(), nil
+
+	stats.Processed = srcV.Len()
+	stats.Goroutines = srcV.Len() + 1 // 1 for each entry, plus the queue processor
+	stats.Duration = time.Since(start)
+	if panicked != nil {
+		return nil, stats, panicked
+	}
+
+	return ptrToElementOfSliceT.Interface(), stats, nil
 }
@@ -1,6 +1,7 @@
 package filter_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -50,6 +51,22 @@ func TestParallelFilter(t *testing.T) {
 	}
 }
 
+func TestParallelFilter_Panic(t *testing.T) {
+	got, err := filter.ParallelFilter([]int{1, 2, 3, 4}, func(entry int) bool {
+		if entry == 3 {
+			panic("I AM INEVITABLE")
+		}
+		return true
+	})
+
+	if !errors.Is(err, filter.ErrFilterPanic) {
+		t.Errorf("ParallelFilter() error = %v, want %v", err, filter.ErrFilterPanic)
+	}
+	if got != nil {
+		t.Errorf("ParallelFilter() = %v, want nil", got)
+	}
+}
+
 func BenchmarkParallelFilter(b *testing.B) {
 	source := [100]int{}
 	for i := 0; i < len(source); i++ {
@@ -117,3 +134,31 @@ func BenchmarkImperativeFast(b *testing.B) {
 		}
 	}
 }
+
+func TestFilterWithStats(t *testing.T) {
+	source := []int{1, 2, 3, 4, 5, 6}
+	isMultipliedBy3 := func(num int) bool {
+		return num%3 == 0
+	}
+
+	got, stats, err := filter.FilterWithStats(source, isMultipliedBy3)
+	if err != nil {
+		t.Fatalf("FilterWithStats() error = %v", err)
+	}
+	if len(got.([]int)) != 2 {
+		t.Errorf("FilterWithStats() = %v, want 2 entries", got)
+	}
+	if stats.Processed != 6 || stats.Matched != 2 {
+		t.Errorf("FilterWithStats() processed = %v matched = %v, want 6 and 2", stats.Processed, stats.Matched)
+	}
+	if stats.Goroutines != 7 {
+		t.Errorf("FilterWithStats() goroutines = %v, want 7", stats.Goroutines)
+	}
+	if stats.Duration <= 0 {
+		t.Errorf("FilterWithStats() duration = %v, want positive duration", stats.Duration)
+	}
+
+	if _, _, err := filter.FilterWithStats("[]int{1, 2, 3}", isMultipliedBy3); err != filter.ErrSourceNotArray {
+		t.Errorf("FilterWithStats() error = %v, want ErrSourceNotArray", err)
+	}
+}
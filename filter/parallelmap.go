@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// ParallelMap an array of T into []U using at most workers go routine
+// mapper is a func(T) U, and unlike ParallelFilter, results are kept in the order of source
+// workers <= 0 defaults to the number of CPU
+func ParallelMap(source, mapper interface{}, workers int) (interface{}, error) {
+	srcV := reflect.ValueOf(source)
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, ErrSourceNotArray
+	}
+
+	if mapper == nil {
+		return nil, ErrFilterFuncNil
+	}
+
+	mv := reflect.ValueOf(mapper)
+	if mv.Kind() != reflect.Func || mv.Type().NumIn() != 1 || mv.Type().NumOut() != 1 {
+		return nil, ErrFilterNotFunc
+	}
+
+	n := srcV.Len()
+	U := mv.Type().Out(0) // Get type U of mapper's result
+	result := reflect.MakeSlice(reflect.SliceOf(U), n, n)
+
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	// each worker maps entries by index, and writes into the same index of result
+	indexes := make(chan int)
+	wg := &sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				mapped := mv.Call([]reflect.Value{srcV.Index(i)})[0]
+				result.Index(i).Set(mapped)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return result.Interface(), nil
+}
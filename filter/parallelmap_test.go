@@ -0,0 +1,107 @@
+package filter_test
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bastianrob/go-experiences/filter"
+)
+
+func TestParallelMap(t *testing.T) {
+	type args struct {
+		arr     interface{}
+		mapperf interface{}
+		workers int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		want    interface{}
+	}{
+		{"Order is kept", args{
+			arr: []int{5, 4, 3, 2, 1},
+			mapperf: func(entry int) string {
+				// earlier entries finish last
+				time.Sleep(time.Duration(entry) * time.Millisecond)
+				return strconv.Itoa(entry)
+			},
+			workers: 5}, false, []string{"5", "4", "3", "2", "1"}},
+		{"Array source", args{
+			arr: [3]int{1, 2, 3},
+			mapperf: func(entry int) int {
+				return entry * 2
+			},
+			workers: 2}, false, []int{2, 4, 6}},
+		{"Default workers", args{
+			arr: []int{1, 2, 3},
+			mapperf: func(entry int) int {
+				return entry * 2
+			},
+			workers: 0}, false, []int{2, 4, 6}},
+		{"Empty source", args{
+			arr: []int{},
+			mapperf: func(entry int) int {
+				return entry
+			},
+			workers: 4}, false, []int{}},
+		{"Failed", args{
+			arr: []int{1, 2},
+			mapperf: func(entry int) (int, error) {
+				return entry, nil
+			},
+			workers: 2}, true, nil},
+		{"Failed", args{
+			arr:     "[]int{1, 2, 3, 4}",
+			mapperf: nil}, true, nil},
+		{"Failed", args{
+			arr:     []int{1, 2},
+			mapperf: nil}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filter.ParallelMap(tt.args.arr, tt.args.mapperf, tt.args.workers)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParallelMap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParallelMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkParallelMap(b *testing.B) {
+	source := [100]int{}
+	for i := 0; i < len(source); i++ {
+		source[i] = i + 1
+	}
+	slowDouble := func(num int) int {
+		time.Sleep(1 * time.Millisecond)
+		return num * 2
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		filter.ParallelMap(source, slowDouble, 10)
+	}
+}
+
+func BenchmarkSerialMap(b *testing.B) {
+	source := make([]int, 100)
+	for i := 0; i < len(source); i++ {
+		source[i] = i + 1
+	}
+	slowDouble := func(num int) int {
+		time.Sleep(1 * time.Millisecond)
+		return num * 2
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		filter.MapG(source, slowDouble)
+	}
+}
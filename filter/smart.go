@@ -0,0 +1,30 @@
+package filter
+
+import (
+	"reflect"
+)
+
+// DefaultSmartThreshold is the source length from which SmartFilter goes parallel
+// Go routine overhead dominates fast filter functions on smaller arrays
+const DefaultSmartThreshold = 1000
+
+// SmartFilter an array, without go routine when source length is below threshold
+// and using go routine otherwise, in which case order of results is not guaranteed
+// threshold <= 0 uses DefaultSmartThreshold
+func SmartFilter(source, filter interface{}, threshold int) (interface{}, error) {
+	if threshold <= 0 {
+		threshold = DefaultSmartThreshold
+	}
+
+	srcV := reflect.ValueOf(source)
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, ErrSourceNotArray
+	}
+
+	if srcV.Len() < threshold {
+		return Filter(source, filter)
+	}
+
+	return ParallelFilter(source, filter)
+}
@@ -0,0 +1,98 @@
+package filter_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/bastianrob/go-experiences/filter"
+)
+
+func TestSmartFilter(t *testing.T) {
+	isOdd := func(entry int) bool {
+		return entry%2 == 1
+	}
+	type args struct {
+		arr       interface{}
+		filterf   interface{}
+		threshold int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		want    interface{}
+	}{
+		{"Serial", args{
+			arr:       []int{1, 2, 3, 4},
+			filterf:   isOdd,
+			threshold: 10}, false, []int{1, 3}},
+		{"Parallel", args{
+			arr:       []int{1, 2, 3, 4},
+			filterf:   isOdd,
+			threshold: 2}, false, []int{1, 3}},
+		{"Default threshold", args{
+			arr:       []int{1, 2, 3, 4},
+			filterf:   isOdd,
+			threshold: 0}, false, []int{1, 3}},
+		{"Failed", args{
+			arr:       "[]int{1, 2, 3, 4}",
+			filterf:   isOdd,
+			threshold: 10}, true, nil},
+		{"Failed", args{
+			arr:       []int{1, 2, 3, 4},
+			filterf:   nil,
+			threshold: 2}, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filter.SmartFilter(tt.args.arr, tt.args.filterf, tt.args.threshold)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SmartFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != nil {
+				sort.Ints(got.([]int)) // parallel path does not guarantee order
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SmartFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// SmartFilter should be as fast as Filter on a small array with fast filter
+// compare with BenchmarkFilterFast and BenchmarkParallelFilterFast
+func BenchmarkSmartFilterFast(b *testing.B) {
+	source := [100]int{}
+	for i := 0; i < len(source); i++ {
+		source[i] = i + 1
+	}
+	isMultipliedBy3 := func(num int) bool {
+		return num%3 == 0
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		filter.SmartFilter(source, isMultipliedBy3, filter.DefaultSmartThreshold)
+	}
+}
+
+// SmartFilter should be as fast as ParallelFilter on an array above threshold with slow filter
+// compare with BenchmarkParallelFilter and BenchmarkImperative
+func BenchmarkSmartFilterSlow(b *testing.B) {
+	source := [100]int{}
+	for i := 0; i < len(source); i++ {
+		source[i] = i + 1
+	}
+	isMultipliedBy3 := func(num int) bool {
+		time.Sleep(20 * time.Millisecond)
+		return num%3 == 0
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		filter.SmartFilter(source, isMultipliedBy3, 10)
+	}
+}
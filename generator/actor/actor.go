@@ -1,9 +1,24 @@
 package actor
 
 import (
+	"errors"
+	"hash/fnv"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// ErrSkip is returned by a processor to drop a message silently
+// A skipped message is neither sent to outbox nor to exception handler
+var ErrSkip = errors.New("Message is skipped")
+
+// tick is the type of TickMessage, unexported so it never collides with a queued message
+type tick struct{}
+
+// TickMessage is injected into the inbox every Options.Tick, e.g: to flush accumulated state
+// Processor handles it like any other message, return ErrSkip to not forward anything
+var TickMessage interface{} = tick{}
+
 // Processor is the delegate which process a message
 // @worker is its assigned worker number (starts from 1) in case we make more than 1 worker
 // @actor is the reference to which actor that receives the message
@@ -13,40 +28,68 @@ type Processor func(worker int, actor *Actor, message interface{}) (interface{},
 // Exception handler in case processor produce an error
 // @worker is its assigned worker number (starts from 1) in case we make more than 1 worker
 // @actor is the reference to which actor that receives the message
+// @message is the original message which failed to be processed
 // @err is the error that happened after trying to process a message
-type Exception func(worker int, actor *Actor, err error)
+type Exception func(worker int, actor *Actor, message interface{}, err error)
+
+// Partitioner get the partition key of a message
+// Messages with the same key are always processed by the same worker, in the order they are queued
+type Partitioner func(message interface{}) string
 
 // Options when initializeing an Actor
 type Options struct {
-	Name        string       // actor's name
-	Worker      int          // number of worker / processor go routine, defaults = 1
-	Output      *Actor       // output actor, on which source actor will send a message after process is done
-	FailChannel chan<- error // failure channel, on which Actor will send in case there is an error
+	Name          string             // actor's name
+	Worker        int                // number of worker / processor go routine, defaults = 1
+	InboxSize     int                // inbox buffer size, defaults = number of worker
+	PartitionKey  Partitioner        // when set, each worker has its own inbox and messages are assigned by key
+	Tick          time.Duration      // when set, TickMessage is injected every tick, to each partition when partitioned
+	Output        *Actor             // output actor, on which source actor will send a message after process is done
+	FailChannel   chan<- error       // failure channel, on which Actor will send in case there is an error, without blocking when it's full
+	ResultChannel chan<- interface{} // result channel, on which terminal Actor without output will send its processed result
 }
 
 func (opt *Options) configure() {
 	if opt.Worker <= 0 {
 		opt.Worker = 1
 	}
+	if opt.InboxSize <= 0 {
+		opt.InboxSize = opt.Worker
+	}
 }
 
 // Actor ...
 type Actor struct {
+	// round robin turn, first in struct to keep it 64-bit aligned for atomic operation
+	turn uint64
+
 	// metadata
-	name string
+	name    string
+	workers int
 
 	// actor mechanism
-	inbox  chan interface{}
-	outbox *Actor
+	inbox      chan interface{}
+	partitions []chan interface{} // per worker inbox, used instead of inbox when partition is set
+	partition  Partitioner
+	tick       time.Duration
+	wiring     sync.RWMutex // guards outbox, targets and exception, so they can be rewired while workers are running
+	outbox     *Actor
+	targets    []*Actor // round robin targets, used instead of outbox when set
 
-	failure   chan error
+	failure   chan<- error
+	results   chan<- interface{}
 	process   Processor
 	exception Exception
 
 	// exit mechanism
 	exit       chan struct{}
+	stopping   sync.RWMutex    // guards exit, so a request is never queued after actor is stopped
 	workgroup  *sync.WaitGroup // worker wait group
 	inboxgroup *sync.WaitGroup // inbox wait group
+
+	// drain mechanism, collects errors of in-flight messages while stopping
+	drainmux sync.Mutex
+	draining bool
+	drained  []error
 }
 
 // New instance of an Actor with w as number of worker
@@ -55,8 +98,13 @@ func New(p Processor, e Exception, opt *Options) *Actor {
 
 	actor := &Actor{
 		name:      opt.Name,
-		inbox:     make(chan interface{}, opt.Worker),
+		workers:   opt.Worker,
+		inbox:     make(chan interface{}, opt.InboxSize),
 		outbox:    opt.Output,
+		partition: opt.PartitionKey,
+		tick:      opt.Tick,
+		failure:   opt.FailChannel,
+		results:   opt.ResultChannel,
 		process:   p,
 		exception: e,
 
@@ -65,10 +113,67 @@ func New(p Processor, e Exception, opt *Options) *Actor {
 		inboxgroup: &sync.WaitGroup{},
 	}
 
+	if actor.partition != nil {
+		actor.partitions = make([]chan interface{}, opt.Worker)
+		for i := range actor.partitions {
+			actor.partitions[i] = make(chan interface{}, opt.InboxSize)
+		}
+	}
+
 	actor.start(0, opt.Worker)
+	if actor.tick > 0 {
+		actor.workgroup.Add(1) // so inboxes are only closed after ticker exits
+		go actor.ticker()
+	}
+
 	return actor
 }
 
+// ticker injects TickMessage every tick until actor is stopped
+// A tick is dropped when the inbox is full, so ticks never pile up behind a busy worker
+func (actor *Actor) ticker() {
+	defer actor.workgroup.Done()
+
+	ticker := time.NewTicker(actor.tick)
+	defer ticker.Stop()
+
+	inboxes := []chan interface{}{actor.inbox}
+	if actor.partitions != nil {
+		inboxes = actor.partitions
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, inbox := range inboxes {
+				actor.inboxgroup.Add(1)
+				select {
+				case inbox <- TickMessage:
+				default:
+					actor.inboxgroup.Done()
+				}
+			}
+		case <-actor.exit:
+			return
+		}
+	}
+}
+
+// Name of the actor, as given in Options
+func (actor *Actor) Name() string {
+	return actor.name
+}
+
+// Workers get the number of worker go routine
+func (actor *Actor) Workers() int {
+	return actor.workers
+}
+
+// InboxCap get the buffer size of inbox, or of each worker's inbox when partitioned
+func (actor *Actor) InboxCap() int {
+	return cap(actor.inbox)
+}
+
 // start the actor with n number of worker
 func (actor *Actor) start(idx, n int) {
 	if idx == n {
@@ -76,31 +181,60 @@ func (actor *Actor) start(idx, n int) {
 	}
 
 	// worker number starts from 1
+	// worker group is added before the worker runs, so Stop always waits for it
+	actor.workgroup.Add(1)
 	go actor.work(idx + 1)
 	actor.start(idx+1, n)
 }
 
 func (actor *Actor) work(w int) {
-	actor.workgroup.Add(1)       // worker group is added
 	defer actor.workgroup.Done() // defer worker group done
 
+	inbox := actor.inbox
+	if actor.partitions != nil {
+		inbox = actor.partitions[w-1]
+	}
+
 	for {
 		select {
-		case message := <-actor.inbox: // waits for message to come from inbox
+		case message := <-inbox: // waits for message to come from inbox
+			if req, ok := message.(*request); ok {
+				actor.respond(w, req)
+				actor.inboxgroup.Done() // flag 1 message as done
+				continue
+			}
+
 			result, err := actor.process(w, actor, message)
 
-			if err != nil && actor.exception != nil {
-				actor.exception(w, actor, err)
+			if errors.Is(err, ErrSkip) {
 				actor.inboxgroup.Done() // flag 1 message as done
 				continue
 			}
 
-			if actor.outbox != nil {
-				actor.outbox.Queue(result)
+			if err != nil {
+				actor.drain(err)
+			}
+
+			if err != nil && (actor.handler() != nil || actor.failure != nil) {
+				actor.fail(w, message, err)
 				actor.inboxgroup.Done() // flag 1 message as done
 				continue
 			}
 
+			if outbox := actor.next(); outbox != nil {
+				outbox.Queue(retrace(message, result))
+				actor.inboxgroup.Done() // flag 1 message as done
+				continue
+			}
+
+			if actor.results != nil {
+				// waits for caller to consume the result, unless actor is stopped
+				select {
+				case actor.results <- result:
+				case <-actor.exit:
+				}
+			}
+
 			// flag 1 message as done
 			actor.inboxgroup.Done()
 		case <-actor.exit: // listen on exit signal
@@ -109,35 +243,170 @@ func (actor *Actor) work(w int) {
 	}
 }
 
+// next actor to send the result to, cycling through round robin targets if any
+// Returns nil for terminal actor
+func (actor *Actor) next() *Actor {
+	actor.wiring.RLock()
+	defer actor.wiring.RUnlock()
+
+	if n := uint64(len(actor.targets)); n > 0 {
+		turn := atomic.AddUint64(&actor.turn, 1) - 1
+		return actor.targets[turn%n]
+	}
+
+	return actor.outbox
+}
+
+// handler get the exception handler, which a pipeline may wrap while workers are running
+func (actor *Actor) handler() Exception {
+	actor.wiring.RLock()
+	defer actor.wiring.RUnlock()
+
+	return actor.exception
+}
+
+// fail forwards the error to exception handler, and to failure channel if any
+// Sending to failure channel never blocks, the error is dropped when it's full
+func (actor *Actor) fail(w int, message interface{}, err error) {
+	if exception := actor.handler(); exception != nil {
+		exception(w, actor, message, err)
+	}
+
+	if actor.failure != nil {
+		select {
+		case actor.failure <- err:
+		default:
+		}
+	}
+}
+
+// route a message to the inbox of its partition, or to the shared inbox
+func (actor *Actor) route(message interface{}) chan interface{} {
+	if actor.partitions == nil {
+		return actor.inbox
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(actor.partition(Untrace(unwrap(message)))))
+	return actor.partitions[hash.Sum32()%uint32(len(actor.partitions))]
+}
+
+// inboxes get the shared inbox and all partition inboxes
+func (actor *Actor) inboxes() []chan interface{} {
+	return append([]chan interface{}{actor.inbox}, actor.partitions...)
+}
+
 // Queue a message to inbox
+// Messages of a single Queue call are sent in order
 func (actor *Actor) Queue(messages ...interface{}) {
 	// add length of message to inbox wait group
 	actor.inboxgroup.Add(len(messages))
 	go func() {
 		for _, message := range messages {
-			actor.inbox <- message
+			actor.route(message) <- message
 		}
 	}()
 }
 
+// TryQueue a message to inbox without blocking, so producers can shed load when actor is overloaded
+// Messages are queued in order until an inbox is full
+// Returns the number of messages accepted, the rest messages[accepted:] are not queued
+func (actor *Actor) TryQueue(messages ...interface{}) (accepted int) {
+	for _, message := range messages {
+		actor.inboxgroup.Add(1)
+		select {
+		case actor.route(message) <- message:
+			accepted++
+		default:
+			actor.inboxgroup.Done()
+			return accepted
+		}
+	}
+
+	return accepted
+}
+
 // Stop actor from processing any message
 func (actor *Actor) Stop() (pendings []interface{}) {
+	pendings, _ = actor.stop(nil)
+	return pendings
+}
+
+// StopWithErrors stop actor like Stop, and also returns the errors of messages which were in-flight while stopping
+func (actor *Actor) StopWithErrors() (pendings []interface{}, errs []error) {
+	actor.drainmux.Lock()
+	actor.draining = true
+	actor.drainmux.Unlock()
+
+	pendings = actor.Stop()
+
+	actor.drainmux.Lock()
+	defer actor.drainmux.Unlock()
+	return pendings, actor.drained
+}
+
+// drain collects the error of a processed message, only while stopping with errors
+func (actor *Actor) drain(err error) {
+	actor.drainmux.Lock()
+	if actor.draining {
+		actor.drained = append(actor.drained, err)
+	}
+	actor.drainmux.Unlock()
+}
+
+// StopWithTimeout stop actor like Stop, but gives up waiting for workers after d
+// Returns the pending messages gathered so far, and whether it timed out because a worker is stuck processing a message
+// After timing out, the remaining stop mechanism exits as soon as the stuck worker returns
+func (actor *Actor) StopWithTimeout(d time.Duration) (pendings []interface{}, timedOut bool) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	return actor.stop(timer.C)
+}
+
+// stop all worker, gather pending messages, and close the inboxes
+// Waits until all workers exit, or until timeout
+func (actor *Actor) stop(timeout <-chan time.Time) (pendings []interface{}, timedOut bool) {
 	// stop all worker from processing any inbox
+	actor.stopping.Lock()
 	close(actor.exit)
-	actor.workgroup.Wait()
+	actor.stopping.Unlock()
 
-	// gather pending messages inside inbox and flag it as done
+	// gather pending messages inside all inboxes and flag it as done
+	// gathering starts right away, so a stuck worker does not hold back the pending messages
+	mux := sync.Mutex{}
+	for _, inbox := range actor.inboxes() {
+		go func(inbox chan interface{}) {
+			for message := range inbox {
+				if message != TickMessage {
+					mux.Lock()
+					pendings = append(pendings, unwrap(message))
+					mux.Unlock()
+				}
+				actor.inboxgroup.Done()
+			}
+		}(inbox)
+	}
+
+	// wait for all worker to exit and pending messages gathering to be completed, then close the inbox channels
+	done := make(chan struct{})
 	go func() {
-		for message := range actor.inbox {
-			pendings = append(pendings, message)
-			actor.inboxgroup.Done()
+		actor.workgroup.Wait()
+		actor.inboxgroup.Wait()
+		for _, inbox := range actor.inboxes() {
+			close(inbox)
 		}
+		close(done)
 	}()
 
-	// wait for pending messages gathering to be completed and close the inbox channel
-	actor.inboxgroup.Wait()
-	close(actor.inbox)
+	select {
+	case <-done:
+	case <-timeout:
+		timedOut = true
+	}
 
-	// return gathered pending messages
-	return pendings
+	// return a copy of gathered pending messages, as gathering may continue after timed out
+	mux.Lock()
+	defer mux.Unlock()
+	return append([]interface{}(nil), pendings...), timedOut
 }
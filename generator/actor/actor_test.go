@@ -1,10 +1,14 @@
 package actor
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func Test_Actor(t *testing.T) {
@@ -18,7 +22,7 @@ func Test_Actor(t *testing.T) {
 			"send to?", actor.outbox)
 
 		return result, nil
-	}, func(w int, actor *Actor, err error) {
+	}, func(w int, actor *Actor, message interface{}, err error) {
 		fmt.Println(err)
 	}, &Options{Worker: 3})
 
@@ -45,16 +49,24 @@ func Test_ActorStop(t *testing.T) {
 		mux.Unlock()
 
 		return nil, nil
-	}, func(w int, actor *Actor, err error) {
+	}, func(w int, actor *Actor, message interface{}, err error) {
 		fmt.Println(err)
 	}, &Options{Worker: 5})
 
 	expected := 0
+	queued := sync.WaitGroup{}
 	for i := 1; i <= 100; i++ {
-		go actor.Queue(i)
+		queued.Add(1)
+		go func(i int) {
+			actor.Queue(i)
+			queued.Done()
+		}(i)
 		expected = expected + i
 	}
 
+	// all messages must be queued before stopping, otherwise they are neither processed nor pending
+	queued.Wait()
+
 	pendings := actor.Stop()
 	combined := append(processed, pendings...)
 
@@ -71,8 +83,8 @@ func Test_ActorStop(t *testing.T) {
 }
 
 func Test_ActorDirected(t *testing.T) {
-	errPrinter := func(w int, actor *Actor, err error) {
-		fmt.Println("worker:", w, "actor:", actor.name, "err:", err)
+	errPrinter := func(w int, actor *Actor, message interface{}, err error) {
+		fmt.Println("worker:", w, "actor:", actor.Name(), "err:", err)
 	}
 
 	bale := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
@@ -97,7 +109,7 @@ func Test_ActorDirected(t *testing.T) {
 		Name:   "Bane",
 	})
 	subtitle := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
-		fmt.Println("worker:", w, "actor:", actor.name, "receive:", in)
+		fmt.Println("worker:", w, "actor:", actor.Name(), "receive:", in)
 		if in != "I AM INEVITABLE" && in != "I AM BANE" && in != "I WILL BREAK YOU" {
 			t.Error("Bane's subtitle must be one of:", "I AM INEVITABLE", "I AM BANE", "I WILL BREAK YOU")
 		}
@@ -114,3 +126,687 @@ func Test_ActorDirected(t *testing.T) {
 	bane.Stop()
 	subtitle.Stop()
 }
+
+func Test_ActorFailChannel(t *testing.T) {
+	failures := make(chan error, 10)
+	fail := func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("%s failed to process %v", actor.Name(), in)
+	}
+
+	// both actors send their failure to the same collector channel
+	joker := New(fail, nil, &Options{Worker: 2, Name: "Joker", FailChannel: failures})
+	riddler := New(fail, nil, &Options{Worker: 2, Name: "Riddler", FailChannel: failures})
+	defer joker.Stop()
+	defer riddler.Stop()
+
+	joker.Queue(1, 2)
+	riddler.Queue(3)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-failures:
+			fmt.Println("collected:", err)
+		case <-time.After(1 * time.Second):
+			t.Fatal("Failures of all actors should be collected, got:", i)
+		}
+	}
+}
+
+func Test_ActorFailChannelFull(t *testing.T) {
+	handled := make(chan error, 3)
+	failures := make(chan error) // nobody listens, so it's always full
+	actor := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		return nil, errors.New("failed")
+	}, func(w int, actor *Actor, message interface{}, err error) {
+		handled <- err
+	}, &Options{Worker: 1, FailChannel: failures})
+	defer actor.Stop()
+
+	actor.Queue(1, 2, 3)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-handled:
+		case <-time.After(1 * time.Second):
+			t.Fatal("Worker must not be blocked by a full failure channel, handled:", i)
+		}
+	}
+}
+
+func Test_ActorResultChannel(t *testing.T) {
+	results := make(chan interface{})
+	actor := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		return in.(int) * 2, nil
+	}, nil, &Options{Worker: 3, ResultChannel: results})
+	defer actor.Stop()
+
+	actor.Queue(1, 2, 3)
+
+	sum := 0
+	for i := 0; i < 3; i++ {
+		select {
+		case result := <-results:
+			sum += result.(int)
+		case <-time.After(1 * time.Second):
+			t.Fatal("Results should be sent to result channel, got:", i)
+		}
+	}
+
+	if sum != 12 {
+		t.Error("Sum of results must be 12, got:", sum)
+	}
+}
+
+func Test_ActorRoundRobin(t *testing.T) {
+	echo := func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		return in, nil
+	}
+
+	wg := sync.WaitGroup{}
+	mux := sync.Mutex{}
+	received := map[string]int{}
+	count := func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		mux.Lock()
+		received[actor.Name()]++
+		mux.Unlock()
+		wg.Done()
+		return nil, nil
+	}
+
+	source := New(echo, nil, &Options{Worker: 4, Name: "Source"})
+	robin := New(count, nil, &Options{Worker: 2, Name: "Robin"})
+	batgirl := New(count, nil, &Options{Worker: 2, Name: "Batgirl"})
+	nightwing := New(count, nil, &Options{Worker: 2, Name: "Nightwing"})
+	RoundRobin(source, robin, batgirl, nightwing)
+
+	messages := make([]interface{}, 300)
+	for i := range messages {
+		messages[i] = i
+	}
+	wg.Add(len(messages))
+	source.Queue(messages...)
+	wg.Wait()
+
+	for _, name := range []string{"Robin", "Batgirl", "Nightwing"} {
+		if received[name] != 100 {
+			t.Error(name, "should receive exactly 100 messages, got:", received[name])
+		}
+	}
+}
+
+func Test_ActorRequest(t *testing.T) {
+	actor := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		if in == "HEY HO!" {
+			return nil, errors.New("WHATEVER YOU SAY")
+		}
+		return "I AM BANE", nil
+	}, nil, &Options{Worker: 2})
+	defer actor.Stop()
+
+	result, err := actor.Request("I AM THE NIGHT")
+	if err != nil || result != "I AM BANE" {
+		t.Error("Request should return the processed result, got:", result, err)
+	}
+
+	if _, err := actor.Request("HEY HO!"); err == nil {
+		t.Error("Request should return the processing error")
+	}
+}
+
+func Test_ActorRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	actor := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		<-release
+		return in, nil
+	}, nil, &Options{Worker: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := actor.RequestContext(ctx, "slow"); err != context.DeadlineExceeded {
+		t.Error("Abandoned request should return context error, got:", err)
+	}
+
+	// the abandoned request is still processed without blocking the worker
+	close(release)
+	if result, err := actor.Request("next"); err != nil || result != "next" {
+		t.Error("Worker should not be blocked by an abandoned request, got:", result, err)
+	}
+	actor.Stop()
+}
+
+func Test_ActorRequestAfterStop(t *testing.T) {
+	actor := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		return in, nil
+	}, nil, &Options{Worker: 1})
+	actor.Stop()
+
+	if _, err := actor.Request("too late"); err != ErrStopped {
+		t.Error("Request on a stopped actor should return ErrStopped, got:", err)
+	}
+	time.Sleep(10 * time.Millisecond) // a queued request would panic sending to the closed inbox
+}
+
+func Test_Pipeline(t *testing.T) {
+	bale := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		return in, nil
+	}, nil, &Options{Worker: 3, Name: "Bale"})
+	bane := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		if in == "I AM THE NIGHT" {
+			return "I AM BANE", nil
+		}
+		return nil, errors.New("WHATEVER YOU SAY")
+	}, nil, &Options{Worker: 3, Name: "Bane"})
+	results := make(chan interface{}, 1)
+	subtitle := New(func(w int, actor *Actor, in interface{}) (interface{}, error) {
+		results <- in
+		return nil, nil
+	}, nil, &Options{Worker: 3, Name: "Subtitle"})
+
+	pipeline := NewPipeline(bale, bane, subtitle)
+	pipeline.Queue("I AM THE NIGHT", "HEY HO!")
+
+	select {
+	case err := <-pipeline.Errors():
+		if err.Stage != "Bane" || err.Err.Error() != "WHATEVER YOU SAY" {
+			t.Error("Error should surface from Bane stage, got:", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Error("Error of middle stage should surface on pipeline errors")
+	}
+
+	select {
+	case in := <-results:
+		if in != "I AM BANE" {
+			t.Error("Subtitle should receive I AM BANE, got:", in)
+		}
+	case <-time.After(1 * time.Second):
+		t.Error("Result should flow through all stages")
+	}
+
+	pipeline.Stop()
+	if _, open := <-pipeline.Errors(); open {
+		t.Error("Pipeline errors should be closed after stop")
+	}
+}
+
+func Test_ActorInboxSize(t *testing.T) {
+	gate := make(chan struct{})
+	actor := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		<-gate // wedged until the whole burst is queued
+		return nil, nil
+	}, nil, &Options{Worker: 1, InboxSize: 100})
+
+	burst := make([]interface{}, 50)
+	for i := range burst {
+		burst[i] = i
+	}
+	actor.Queue(burst...)
+
+	// 1 message is being processed, the rest must be buffered in inbox
+	deadline := time.After(1 * time.Second)
+	for len(actor.inbox) < len(burst)-1 {
+		select {
+		case <-deadline:
+			t.Fatal("Burst should be buffered without blocking the producer, got:", len(actor.inbox))
+		case <-time.After(1 * time.Millisecond):
+		}
+	}
+
+	close(gate)
+	actor.Stop()
+}
+
+func Test_ActorPartitionKey(t *testing.T) {
+	type keyed struct {
+		key string
+		seq int
+	}
+
+	mux := sync.Mutex{}
+	workers := map[string]map[int]bool{}
+	processed := map[string][]int{}
+	results := make(chan interface{})
+	actor := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		msg := message.(keyed)
+		time.Sleep(time.Millisecond) // give other workers a chance to steal the key, if they could
+
+		mux.Lock()
+		if workers[msg.key] == nil {
+			workers[msg.key] = map[int]bool{}
+		}
+		workers[msg.key][w] = true
+		processed[msg.key] = append(processed[msg.key], msg.seq)
+		mux.Unlock()
+
+		return msg, nil
+	}, nil, &Options{
+		Worker:        4,
+		ResultChannel: results,
+		PartitionKey: func(message interface{}) string {
+			return message.(keyed).key
+		},
+	})
+
+	keys := []string{"A", "B", "C", "D", "E"}
+	var messages []interface{}
+	for seq := 0; seq < 20; seq++ {
+		for _, key := range keys {
+			messages = append(messages, keyed{key, seq})
+		}
+	}
+	actor.Queue(messages...)
+
+	for range messages {
+		select {
+		case <-results:
+		case <-time.After(1 * time.Second):
+			t.Fatal("All keyed messages should be processed")
+		}
+	}
+	actor.Stop()
+
+	for _, key := range keys {
+		if len(workers[key]) != 1 {
+			t.Error("Messages of key", key, "should be processed by one worker, got:", workers[key])
+		}
+		for i, seq := range processed[key] {
+			if seq != i {
+				t.Error("Messages of key", key, "should be processed in order, got:", processed[key])
+				break
+			}
+		}
+	}
+}
+
+func Test_ActorStopWithTimeout(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	gate := make(chan struct{})
+	processing := make(chan struct{}, 1)
+	actor := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		processing <- struct{}{}
+		<-gate // deliberately stuck
+		return nil, nil
+	}, nil, &Options{Worker: 1, InboxSize: 10})
+
+	actor.Queue(1, 2, 3)
+	<-processing
+
+	start := time.Now()
+	pendings, timedOut := actor.StopWithTimeout(50 * time.Millisecond)
+	if !timedOut {
+		t.Error("Stop should time out while a worker is stuck")
+	}
+	if dur := time.Since(start); dur >= 500*time.Millisecond {
+		t.Error("Stop should give up after the timeout, took:", dur)
+	}
+	if len(pendings) != 2 {
+		t.Error("Messages not yet processed should be gathered as pending, got:", pendings)
+	}
+
+	// once the stuck worker returns, nothing is left running
+	close(gate)
+	deadline := time.After(1 * time.Second)
+	for runtime.NumGoroutine() > before {
+		select {
+		case <-deadline:
+			t.Fatal("Stop should not leak go routines, got:", runtime.NumGoroutine()-before)
+		case <-time.After(1 * time.Millisecond):
+		}
+	}
+}
+
+func Test_ActorExceptionMessage(t *testing.T) {
+	failed := make(chan interface{}, 1)
+	actor := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		return nil, errors.New("boom")
+	}, func(w int, actor *Actor, message interface{}, err error) {
+		failed <- message
+	}, &Options{Worker: 1})
+	defer actor.Stop()
+
+	actor.Queue("THE MESSAGE")
+	select {
+	case message := <-failed:
+		if message != "THE MESSAGE" {
+			t.Error("Exception should receive the failed message, got:", message)
+		}
+	case <-time.After(1 * time.Second):
+		t.Error("Exception should be called")
+	}
+}
+
+func Test_ActorSkip(t *testing.T) {
+	failed := make(chan error, 10)
+	evens := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		if message.(int)%2 != 0 {
+			return nil, ErrSkip
+		}
+		return message, nil
+	}, func(w int, actor *Actor, message interface{}, err error) {
+		failed <- err
+	}, &Options{Worker: 3})
+
+	results := make(chan interface{}, 10)
+	downstream := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		return message, nil
+	}, nil, &Options{Worker: 1, ResultChannel: results})
+	Direct(evens, downstream)
+
+	evens.Queue(1, 2, 3, 4, 5, 6)
+
+	received := map[interface{}]bool{}
+	for i := 0; i < 3; i++ {
+		select {
+		case message := <-results:
+			received[message] = true
+		case <-time.After(1 * time.Second):
+			t.Fatal("Even numbers should reach downstream, got:", received)
+		}
+	}
+	evens.Stop()
+	downstream.Stop()
+
+	if !received[2] || !received[4] || !received[6] {
+		t.Error("Only even numbers should reach downstream, got:", received)
+	}
+	if len(results) != 0 {
+		t.Error("Skipped messages should not reach downstream, got:", <-results)
+	}
+	if len(failed) != 0 {
+		t.Error("Skipped messages should not be reported as failure, got:", <-failed)
+	}
+}
+
+func Test_ActorTryQueue(t *testing.T) {
+	gate := make(chan struct{})
+	processing := make(chan struct{}, 1)
+	actor := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		select {
+		case processing <- struct{}{}:
+		default:
+		}
+		<-gate // wedged until the inbox is filled
+		return nil, nil
+	}, nil, &Options{Worker: 1, InboxSize: 5})
+
+	if accepted := actor.TryQueue(0); accepted != 1 {
+		t.Fatal("Message should be accepted by an empty inbox, got:", accepted)
+	}
+	<-processing
+
+	// 1 message is being processed, so the inbox only have room for 5 out of 8
+	if accepted := actor.TryQueue(1, 2, 3, 4, 5, 6, 7, 8); accepted != 5 {
+		t.Error("Only 5 messages should be accepted by a full inbox, got:", accepted)
+	}
+	if accepted := actor.TryQueue(9); accepted != 0 {
+		t.Error("Message should not be accepted by a full inbox, got:", accepted)
+	}
+
+	close(gate)
+	pendings := actor.Stop()
+	if len(pendings) > 5 {
+		t.Error("Rejected messages must not be pending, got:", pendings)
+	}
+}
+
+func Test_ActorStopWithErrors(t *testing.T) {
+	inflight := sync.WaitGroup{}
+	inflight.Add(4)
+	actor := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		inflight.Done()
+		time.Sleep(50 * time.Millisecond) // still processing when stop is called
+		if message.(int)%2 != 0 {
+			return nil, fmt.Errorf("%d is odd", message)
+		}
+		return message, nil
+	}, nil, &Options{Worker: 4})
+
+	actor.Queue(1, 2, 3, 4)
+	inflight.Wait()
+
+	pendings, errs := actor.StopWithErrors()
+	if len(pendings) != 0 {
+		t.Error("All messages were in-flight, nothing should be pending, got:", pendings)
+	}
+	if len(errs) != 2 {
+		t.Error("Errors of in-flight odd messages should be returned, got:", errs)
+	}
+}
+
+func Test_ActorTrace(t *testing.T) {
+	type observed struct {
+		trace   string
+		message interface{}
+	}
+	head := make(chan observed, 2)
+	tail := make(chan observed, 2)
+
+	first := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		head <- observed{TraceID(message), Untrace(message)}
+		return Untrace(message).(int) * 2, nil
+	}, nil, &Options{Worker: 1})
+	second := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		// a processor which returns its own traced result keeps that trace
+		if TraceID(message) == "" {
+			return WithTrace("untraced", Untrace(message)), nil
+		}
+		return Untrace(message).(int) + 1, nil
+	}, nil, &Options{Worker: 1})
+	third := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		tail <- observed{TraceID(message), Untrace(message)}
+		return nil, nil
+	}, nil, &Options{Worker: 1})
+	Direct(first, second, third)
+
+	first.Queue(WithTrace("TRACE-001", 1))
+	for _, ch := range []chan observed{head, tail} {
+		select {
+		case got := <-ch:
+			if got.trace != "TRACE-001" {
+				t.Error("Trace id should flow through the pipeline, got:", got.trace)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatal("Traced message should reach every stage")
+		}
+	}
+
+	first.Queue(2)
+	if got := <-head; got.trace != "" || got.message != 2 {
+		t.Error("Untraced message should be processed as is, got:", got)
+	}
+	if got := <-tail; got.trace != "untraced" || got.message != 4 {
+		t.Error("Trace id returned by a processor should be kept, got:", got)
+	}
+
+	first.Stop()
+	second.Stop()
+	third.Stop()
+}
+
+func Test_ActorTick(t *testing.T) {
+	var ticks, messages int32
+	interval := 20 * time.Millisecond
+	actor := New(func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		if message == TickMessage {
+			atomic.AddInt32(&ticks, 1)
+			return nil, ErrSkip
+		}
+		atomic.AddInt32(&messages, 1)
+		return message, nil
+	}, nil, &Options{Worker: 1, Tick: interval})
+
+	n := 10
+	actor.Queue(1, 2, 3)
+	time.Sleep(time.Duration(n)*interval + interval/2)
+
+	pendings := actor.Stop()
+	count := atomic.LoadInt32(&ticks)
+	if count < int32(n-2) || count > int32(n) {
+		t.Error("Processor should see around", n, "ticks, got:", count)
+	}
+	if atomic.LoadInt32(&messages) != 3 {
+		t.Error("Ticks should not interfere with queued messages, got:", atomic.LoadInt32(&messages))
+	}
+	if len(pendings) != 0 {
+		t.Error("Ticks must not be reported as pending, got:", pendings)
+	}
+
+	// no more tick after actor is stopped
+	time.Sleep(2 * interval)
+	if atomic.LoadInt32(&ticks) != count {
+		t.Error("Processor must not see ticks after actor is stopped")
+	}
+}
+
+func Test_ActorSetOutput(t *testing.T) {
+	blues := make(chan interface{}, 100)
+	greens := make(chan interface{}, 100)
+	echo := func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		return message, nil
+	}
+	source := New(echo, nil, &Options{Worker: 4})
+	blue := New(echo, nil, &Options{Worker: 1, ResultChannel: blues})
+	green := New(echo, nil, &Options{Worker: 1, ResultChannel: greens})
+	Direct(source, blue)
+
+	receive := func(results chan interface{}, n int) {
+		for i := 0; i < n; i++ {
+			select {
+			case <-results:
+			case <-time.After(1 * time.Second):
+				t.Fatal("Expected", n, "results, got:", i)
+			}
+		}
+	}
+
+	source.Queue(1, 2, 3)
+	receive(blues, 3)
+
+	source.SetOutput(green)
+	source.Queue(4, 5, 6)
+	receive(greens, 3)
+	if len(blues) != 0 {
+		t.Error("Results after rewiring should not reach the previous target, got:", <-blues)
+	}
+
+	// rewiring mid-stream neither drops nor duplicates a result
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			if i%2 == 0 {
+				source.SetOutput(blue)
+			} else {
+				source.SetOutput(green)
+			}
+		}
+		close(done)
+	}()
+	for i := 0; i < 100; i++ {
+		source.Queue(i)
+	}
+	<-done
+
+	received := map[interface{}]bool{}
+	for len(received) < 100 {
+		select {
+		case message := <-blues:
+			received[message] = true
+		case message := <-greens:
+			received[message] = true
+		case <-time.After(1 * time.Second):
+			t.Fatal("All results should reach either target, got:", len(received))
+		}
+	}
+	source.Stop()
+	blue.Stop()
+	green.Stop()
+	if n := len(blues) + len(greens); n != 0 {
+		t.Error("Results should not be duplicated, got:", n, "extra")
+	}
+}
+
+func Test_TypedActor(t *testing.T) {
+	type placeOrder struct {
+		Customer string
+		Qty      int
+	}
+
+	results := make(chan interface{}, 2)
+	failures := make(chan error, 1)
+	orders := NewTyped(func(w int, actor *Actor, cmd *placeOrder) (interface{}, error) {
+		// no type assertion needed, cmd is already a *placeOrder
+		return cmd.Customer + " x" + fmt.Sprint(cmd.Qty), nil
+	}, func(w int, actor *Actor, message interface{}, err error) {
+		failures <- err
+	}, &Options{Worker: 1, ResultChannel: results})
+
+	orders.Queue(&placeOrder{Customer: "CUST-001", Qty: 2})
+	select {
+	case result := <-results:
+		if result != "CUST-001 x2" {
+			t.Error("Typed processor should receive the typed message, got:", result)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Typed message should be processed")
+	}
+
+	// a message of another type can still reach the inbox untyped, e.g: from an upstream actor
+	orders.Actor.Queue("not an order")
+	select {
+	case err := <-failures:
+		if !errors.Is(err, ErrUnexpectedMessageType) {
+			t.Error("Message of another type should fail with ErrUnexpectedMessageType, got:", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Message of another type should go to exception handler")
+	}
+
+	orders.Stop()
+}
+
+func Test_ActorGetters(t *testing.T) {
+	noop := func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	actor := New(noop, nil, &Options{Name: "Bale", Worker: 3, InboxSize: 10})
+	if actor.Name() != "Bale" || actor.Workers() != 3 || actor.InboxCap() != 10 {
+		t.Error("Getters should reflect the options, got:", actor.Name(), actor.Workers(), actor.InboxCap())
+	}
+	actor.Stop()
+
+	// defaults are reflected as well
+	actor = New(noop, nil, &Options{})
+	if actor.Name() != "" || actor.Workers() != 1 || actor.InboxCap() != 1 {
+		t.Error("Getters should reflect the default options, got:", actor.Name(), actor.Workers(), actor.InboxCap())
+	}
+	actor.Stop()
+}
+
+func Test_ActorDownstream(t *testing.T) {
+	noop := func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	bale := New(noop, nil, &Options{Name: "Bale"})
+	bane := New(noop, nil, &Options{Name: "Bane"})
+	subtitle := New(noop, nil, &Options{Name: "Subtitle"})
+	defer bale.Stop()
+	defer bane.Stop()
+	defer subtitle.Stop()
+
+	Direct(bale, bane, subtitle)
+	chain := bale.Downstream()
+	if len(chain) != 2 || chain[0] != bane || chain[1] != subtitle {
+		t.Error("Downstream should be Bane then Subtitle, got:", len(chain), "actors")
+	}
+	if len(subtitle.Downstream()) != 0 {
+		t.Error("Terminal actor should have no downstream")
+	}
+
+	// a cycle stops on revisit
+	subtitle.SetOutput(bale)
+	chain = bale.Downstream()
+	if len(chain) != 2 || chain[0] != bane || chain[1] != subtitle {
+		t.Error("Downstream should stop on revisit, got:", len(chain), "actors")
+	}
+}
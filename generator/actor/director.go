@@ -9,7 +9,48 @@ func Direct(actors ...*Actor) {
 			continue
 		}
 
-		source.outbox = target
+		source.SetOutput(target)
 		source = target
 	}
 }
+
+// RoundRobin distributes the results of source actor across targets, each result goes to exactly one target
+// Unlike Direct, which sends all results to a single target
+func RoundRobin(source *Actor, targets ...*Actor) {
+	source.wiring.Lock()
+	defer source.wiring.Unlock()
+
+	source.outbox = nil
+	source.targets = targets
+}
+
+// SetOutput swaps the outbox of a running actor, e.g: for blue/green routing
+// Results processed afterward are sent to a, while results already forwarded stay in the previous target
+// A nil a turns actor into a terminal actor
+func (actor *Actor) SetOutput(a *Actor) {
+	actor.wiring.Lock()
+	defer actor.wiring.Unlock()
+
+	actor.outbox = a
+	actor.targets = nil
+}
+
+// Downstream get the ordered list of actors a result traverses from actor, following each outbox
+// The walk stops at a terminal actor, at a round robin actor as its results fan out, or when an actor is revisited in a cycle
+func (actor *Actor) Downstream() []*Actor {
+	var chain []*Actor
+	visited := map[*Actor]bool{actor: true}
+	for current := actor; ; {
+		current.wiring.RLock()
+		next := current.outbox
+		current.wiring.RUnlock()
+
+		if next == nil || visited[next] {
+			return chain
+		}
+
+		visited[next] = true
+		chain = append(chain, next)
+		current = next
+	}
+}
@@ -0,0 +1,78 @@
+package actor
+
+// DefaultPipelineBuffer is the number of stage errors a pipeline holds before dropping them
+const DefaultPipelineBuffer = 100
+
+// StageError is an error occurred in one of pipeline stages
+type StageError struct {
+	Stage string // name of the actor which produce the error
+	Err   error
+}
+
+func (se StageError) Error() string {
+	return se.Stage + ": " + se.Err.Error()
+}
+
+// Pipeline of actors, where each stage sends its result to the next stage
+// Errors of all stages are aggregated into a single channel
+type Pipeline struct {
+	stages []*Actor
+	errors chan StageError
+}
+
+// NewPipeline wires the actors in order, like Direct, and aggregates their errors
+// Each stage's own exception handler is still called
+func NewPipeline(stages ...*Actor) *Pipeline {
+	pipeline := &Pipeline{
+		stages: stages,
+		errors: make(chan StageError, DefaultPipelineBuffer),
+	}
+
+	Direct(stages...)
+	for _, stage := range stages {
+		stage.wiring.Lock()
+		stage.exception = pipeline.aggregate(stage.exception)
+		stage.wiring.Unlock()
+	}
+
+	return pipeline
+}
+
+// aggregate wraps a stage's exception handler, to also send its error to pipeline errors
+// Sending never blocks the stage, the error is dropped when errors channel is full
+func (pipeline *Pipeline) aggregate(exception Exception) Exception {
+	return func(w int, actor *Actor, message interface{}, err error) {
+		if exception != nil {
+			exception(w, actor, message, err)
+		}
+
+		select {
+		case pipeline.errors <- StageError{Stage: actor.name, Err: err}:
+		default:
+		}
+	}
+}
+
+// Errors of all stages, closed when pipeline is stopped
+func (pipeline *Pipeline) Errors() <-chan StageError {
+	return pipeline.errors
+}
+
+// Queue messages to the first stage
+func (pipeline *Pipeline) Queue(messages ...interface{}) {
+	if len(pipeline.stages) == 0 {
+		return
+	}
+
+	pipeline.stages[0].Queue(messages...)
+}
+
+// Stop all stages in order, and returns the pending messages of all stages
+func (pipeline *Pipeline) Stop() (pendings []interface{}) {
+	for _, stage := range pipeline.stages {
+		pendings = append(pendings, stage.Stop()...)
+	}
+
+	close(pipeline.errors)
+	return pendings
+}
@@ -0,0 +1,78 @@
+package actor
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStopped when requesting to an actor which have been stopped
+var ErrStopped = errors.New("Actor have been stopped")
+
+// request wraps a message with a reply channel, for synchronous request/response
+type request struct {
+	message interface{}
+	reply   chan reply // buffered, so worker never blocks on an abandoned request
+}
+
+// reply of a processed request
+type reply struct {
+	result interface{}
+	err    error
+}
+
+// Request queue a message and blocks until it is processed
+// Returns the processed result and error, instead of sending them to outbox or exception handler
+func (actor *Actor) Request(message interface{}) (interface{}, error) {
+	return actor.RequestContext(context.Background(), message)
+}
+
+// RequestContext queue a message and blocks until it is processed, or until ctx is done
+// An abandoned request is still processed, but its reply is discarded
+func (actor *Actor) RequestContext(ctx context.Context, message interface{}) (interface{}, error) {
+	req := &request{
+		message: message,
+		reply:   make(chan reply, 1),
+	}
+	if !actor.enqueue(req) {
+		return nil, ErrStopped
+	}
+
+	select {
+	case rep := <-req.reply:
+		return rep.result, rep.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-actor.exit:
+		return nil, ErrStopped
+	}
+}
+
+// enqueue the request unless actor is stopped
+// Checked under the stopping lock, so the request is either queued before exit is closed or not queued at all
+func (actor *Actor) enqueue(req *request) bool {
+	actor.stopping.RLock()
+	defer actor.stopping.RUnlock()
+
+	select {
+	case <-actor.exit:
+		return false
+	default:
+		actor.Queue(req)
+		return true
+	}
+}
+
+// respond process the request message and sends the reply back to requester
+func (actor *Actor) respond(w int, req *request) {
+	result, err := actor.process(w, actor, req.message)
+	req.reply <- reply{result: result, err: err}
+}
+
+// unwrap the original message of a request
+func unwrap(message interface{}) interface{} {
+	if req, ok := message.(*request); ok {
+		return req.message
+	}
+
+	return message
+}
@@ -0,0 +1,42 @@
+package actor
+
+// traced wraps a message with a correlation id, which flows through directed actors
+type traced struct {
+	id      string
+	message interface{}
+}
+
+// WithTrace wraps a message with a correlation id, e.g: before queueing it into the head of a pipeline
+// Processor and exception handler receive the traced message, use TraceID and Untrace to read it
+// When the result is sent to outbox, it is traced with the same id
+func WithTrace(id string, message interface{}) interface{} {
+	return &traced{id: id, message: message}
+}
+
+// TraceID get the correlation id of a traced message, empty if message is not traced
+func TraceID(message interface{}) string {
+	if tr, ok := message.(*traced); ok {
+		return tr.id
+	}
+
+	return ""
+}
+
+// Untrace get the original message of a traced message
+func Untrace(message interface{}) interface{} {
+	if tr, ok := message.(*traced); ok {
+		return tr.message
+	}
+
+	return message
+}
+
+// retrace result with the correlation id of the message it is processed from
+func retrace(message, result interface{}) interface{} {
+	id := TraceID(message)
+	if id == "" || TraceID(result) != "" {
+		return result
+	}
+
+	return WithTrace(id, result)
+}
@@ -0,0 +1,53 @@
+package actor
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnexpectedMessageType when a processor receives a message of a type it does not handle
+var ErrUnexpectedMessageType = errors.New("Message is not of the expected type")
+
+// TypedProcessor is a Processor which receives its message as T, instead of interface{}
+type TypedProcessor[T any] func(worker int, actor *Actor, message T) (interface{}, error)
+
+// TypedActor is an Actor which only accepts messages of type T
+// Queueing a message of another type is caught at compile time, instead of panicking a processor at runtime
+type TypedActor[T any] struct {
+	*Actor
+}
+
+// NewTyped instance of an Actor which only accepts messages of type T
+// A message of another type, e.g: the result of an upstream actor, goes to exception handler as ErrUnexpectedMessageType
+func NewTyped[T any](p TypedProcessor[T], e Exception, opt *Options) *TypedActor[T] {
+	process := func(w int, actor *Actor, message interface{}) (interface{}, error) {
+		typed, ok := Untrace(message).(T)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrUnexpectedMessageType, Untrace(message))
+		}
+
+		return p(w, actor, typed)
+	}
+
+	return &TypedActor[T]{Actor: New(process, e, opt)}
+}
+
+// Queue typed messages to inbox
+func (actor *TypedActor[T]) Queue(messages ...T) {
+	actor.Actor.Queue(untype(messages)...)
+}
+
+// TryQueue typed messages to inbox without blocking, see Actor.TryQueue
+func (actor *TypedActor[T]) TryQueue(messages ...T) (accepted int) {
+	return actor.Actor.TryQueue(untype(messages)...)
+}
+
+// untype converts typed messages into the inbox messages
+func untype[T any](messages []T) []interface{} {
+	untyped := make([]interface{}, len(messages))
+	for i, message := range messages {
+		untyped[i] = message
+	}
+
+	return untyped
+}
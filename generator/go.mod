@@ -1,5 +1,5 @@
 module github.com/bastianrob/go-experiences/generator
 
-go 1.14
+go 1.18
 
 require github.com/google/uuid v1.1.1
@@ -1,30 +1,51 @@
 package mock
 
+import (
+	"context"
+)
+
+//go:generate moq -out crud_moq.go . CRUD
+
 // CRUD contract
+// Every call carries a context, so a cancelled caller can abandon in-flight calls
 type CRUD interface {
-	Get(id string) (interface{}, error)
-	Create(dao interface{}) error
-	Update(dao interface{}) error
+	Get(ctx context.Context, id string) (interface{}, error)
+	Create(ctx context.Context, dao interface{}) error
+	Update(ctx context.Context, dao interface{}) error
+	Delete(ctx context.Context, dao interface{}) error
+	List(ctx context.Context, filter map[string]interface{}) ([]interface{}, error)
 }
 
 // APIClient generic mock implementation of CRUD interface
 type APIClient struct {
-	GetFunc    func(id string) (interface{}, error)
-	CreateFunc func(dao interface{}) error
-	UpdateFunc func(dao interface{}) error
+	GetFunc    func(ctx context.Context, id string) (interface{}, error)
+	CreateFunc func(ctx context.Context, dao interface{}) error
+	UpdateFunc func(ctx context.Context, dao interface{}) error
+	DeleteFunc func(ctx context.Context, dao interface{}) error
+	ListFunc   func(ctx context.Context, filter map[string]interface{}) ([]interface{}, error)
 }
 
 // Get mock, please implement GetFunc
-func (ac *APIClient) Get(id string) (interface{}, error) {
-	return ac.GetFunc(id)
+func (ac *APIClient) Get(ctx context.Context, id string) (interface{}, error) {
+	return ac.GetFunc(ctx, id)
 }
 
 // Create mock, please implement CreateFunc
-func (ac *APIClient) Create(dao interface{}) error {
-	return ac.CreateFunc(dao)
+func (ac *APIClient) Create(ctx context.Context, dao interface{}) error {
+	return ac.CreateFunc(ctx, dao)
 }
 
 // Update mock, please implement UpdateFunc
-func (ac *APIClient) Update(dao interface{}) error {
-	return ac.UpdateFunc(dao)
+func (ac *APIClient) Update(ctx context.Context, dao interface{}) error {
+	return ac.UpdateFunc(ctx, dao)
+}
+
+// Delete mock, please implement DeleteFunc
+func (ac *APIClient) Delete(ctx context.Context, dao interface{}) error {
+	return ac.DeleteFunc(ctx, dao)
+}
+
+// List mock, please implement ListFunc
+func (ac *APIClient) List(ctx context.Context, filter map[string]interface{}) ([]interface{}, error) {
+	return ac.ListFunc(ctx, filter)
 }
@@ -4,198 +4,278 @@
 package mock
 
 import (
+	"context"
 	"sync"
 )
 
-var (
-	lockCRUDMockCreate sync.RWMutex
-	lockCRUDMockFind   sync.RWMutex
-	lockCRUDMockGet    sync.RWMutex
-	lockCRUDMockUpdate sync.RWMutex
-)
+// Ensure, that CRUDMock does implement CRUD.
+// If this is not the case, regenerate this file with moq.
+var _ CRUD = &CRUDMock{}
 
 // CRUDMock is a mock implementation of CRUD.
 //
-//     func TestSomethingThatUsesCRUD(t *testing.T) {
+//	func TestSomethingThatUsesCRUD(t *testing.T) {
 //
-//         // make and configure a mocked CRUD
-//         mockedCRUD := &CRUDMock{
-//             CreateFunc: func(dao interface{}) error {
-// 	               panic("mock out the Create method")
-//             },
-//             FindFunc: func(ids ...string) (interface{}, error) {
-// 	               panic("mock out the Find method")
-//             },
-//             GetFunc: func(id string) (interface{}, error) {
-// 	               panic("mock out the Get method")
-//             },
-//             UpdateFunc: func(dao interface{}) error {
-// 	               panic("mock out the Update method")
-//             },
-//         }
+//		// make and configure a mocked CRUD
+//		mockedCRUD := &CRUDMock{
+//			CreateFunc: func(ctx context.Context, dao interface{}) error {
+//				panic("mock out the Create method")
+//			},
+//			DeleteFunc: func(ctx context.Context, dao interface{}) error {
+//				panic("mock out the Delete method")
+//			},
+//			GetFunc: func(ctx context.Context, id string) (interface{}, error) {
+//				panic("mock out the Get method")
+//			},
+//			ListFunc: func(ctx context.Context, filter map[string]interface{}) ([]interface{}, error) {
+//				panic("mock out the List method")
+//			},
+//			UpdateFunc: func(ctx context.Context, dao interface{}) error {
+//				panic("mock out the Update method")
+//			},
+//		}
 //
-//         // use mockedCRUD in code that requires CRUD
-//         // and then make assertions.
+//		// use mockedCRUD in code that requires CRUD
+//		// and then make assertions.
 //
-//     }
+//	}
 type CRUDMock struct {
 	// CreateFunc mocks the Create method.
-	CreateFunc func(dao interface{}) error
+	CreateFunc func(ctx context.Context, dao interface{}) error
 
-	// FindFunc mocks the Find method.
-	FindFunc func(ids ...string) (interface{}, error)
+	// DeleteFunc mocks the Delete method.
+	DeleteFunc func(ctx context.Context, dao interface{}) error
 
 	// GetFunc mocks the Get method.
-	GetFunc func(id string) (interface{}, error)
+	GetFunc func(ctx context.Context, id string) (interface{}, error)
+
+	// ListFunc mocks the List method.
+	ListFunc func(ctx context.Context, filter map[string]interface{}) ([]interface{}, error)
 
 	// UpdateFunc mocks the Update method.
-	UpdateFunc func(dao interface{}) error
+	UpdateFunc func(ctx context.Context, dao interface{}) error
 
 	// calls tracks calls to the methods.
 	calls struct {
 		// Create holds details about calls to the Create method.
 		Create []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// Dao is the dao argument value.
 			Dao interface{}
 		}
-		// Find holds details about calls to the Find method.
-		Find []struct {
-			// Ids is the ids argument value.
-			Ids []string
+		// Delete holds details about calls to the Delete method.
+		Delete []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Dao is the dao argument value.
+			Dao interface{}
 		}
 		// Get holds details about calls to the Get method.
 		Get []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the id argument value.
 			ID string
 		}
+		// List holds details about calls to the List method.
+		List []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filter is the filter argument value.
+			Filter map[string]interface{}
+		}
 		// Update holds details about calls to the Update method.
 		Update []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// Dao is the dao argument value.
 			Dao interface{}
 		}
 	}
+	lockCreate sync.RWMutex
+	lockDelete sync.RWMutex
+	lockGet    sync.RWMutex
+	lockList   sync.RWMutex
+	lockUpdate sync.RWMutex
 }
 
 // Create calls CreateFunc.
-func (mock *CRUDMock) Create(dao interface{}) error {
+func (mock *CRUDMock) Create(ctx context.Context, dao interface{}) error {
 	if mock.CreateFunc == nil {
 		panic("CRUDMock.CreateFunc: method is nil but CRUD.Create was just called")
 	}
 	callInfo := struct {
+		Ctx context.Context
 		Dao interface{}
 	}{
+		Ctx: ctx,
 		Dao: dao,
 	}
-	lockCRUDMockCreate.Lock()
+	mock.lockCreate.Lock()
 	mock.calls.Create = append(mock.calls.Create, callInfo)
-	lockCRUDMockCreate.Unlock()
-	return mock.CreateFunc(dao)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(ctx, dao)
 }
 
 // CreateCalls gets all the calls that were made to Create.
 // Check the length with:
-//     len(mockedCRUD.CreateCalls())
+//
+//	len(mockedCRUD.CreateCalls())
 func (mock *CRUDMock) CreateCalls() []struct {
+	Ctx context.Context
 	Dao interface{}
 } {
 	var calls []struct {
+		Ctx context.Context
 		Dao interface{}
 	}
-	lockCRUDMockCreate.RLock()
+	mock.lockCreate.RLock()
 	calls = mock.calls.Create
-	lockCRUDMockCreate.RUnlock()
+	mock.lockCreate.RUnlock()
 	return calls
 }
 
-// Find calls FindFunc.
-func (mock *CRUDMock) Find(ids ...string) (interface{}, error) {
-	if mock.FindFunc == nil {
-		panic("CRUDMock.FindFunc: method is nil but CRUD.Find was just called")
+// Delete calls DeleteFunc.
+func (mock *CRUDMock) Delete(ctx context.Context, dao interface{}) error {
+	if mock.DeleteFunc == nil {
+		panic("CRUDMock.DeleteFunc: method is nil but CRUD.Delete was just called")
 	}
 	callInfo := struct {
-		Ids []string
+		Ctx context.Context
+		Dao interface{}
 	}{
-		Ids: ids,
+		Ctx: ctx,
+		Dao: dao,
 	}
-	lockCRUDMockFind.Lock()
-	mock.calls.Find = append(mock.calls.Find, callInfo)
-	lockCRUDMockFind.Unlock()
-	return mock.FindFunc(ids...)
+	mock.lockDelete.Lock()
+	mock.calls.Delete = append(mock.calls.Delete, callInfo)
+	mock.lockDelete.Unlock()
+	return mock.DeleteFunc(ctx, dao)
 }
 
-// FindCalls gets all the calls that were made to Find.
+// DeleteCalls gets all the calls that were made to Delete.
 // Check the length with:
-//     len(mockedCRUD.FindCalls())
-func (mock *CRUDMock) FindCalls() []struct {
-	Ids []string
+//
+//	len(mockedCRUD.DeleteCalls())
+func (mock *CRUDMock) DeleteCalls() []struct {
+	Ctx context.Context
+	Dao interface{}
 } {
 	var calls []struct {
-		Ids []string
+		Ctx context.Context
+		Dao interface{}
 	}
-	lockCRUDMockFind.RLock()
-	calls = mock.calls.Find
-	lockCRUDMockFind.RUnlock()
+	mock.lockDelete.RLock()
+	calls = mock.calls.Delete
+	mock.lockDelete.RUnlock()
 	return calls
 }
 
 // Get calls GetFunc.
-func (mock *CRUDMock) Get(id string) (interface{}, error) {
+func (mock *CRUDMock) Get(ctx context.Context, id string) (interface{}, error) {
 	if mock.GetFunc == nil {
 		panic("CRUDMock.GetFunc: method is nil but CRUD.Get was just called")
 	}
 	callInfo := struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}{
-		ID: id,
+		Ctx: ctx,
+		ID:  id,
 	}
-	lockCRUDMockGet.Lock()
+	mock.lockGet.Lock()
 	mock.calls.Get = append(mock.calls.Get, callInfo)
-	lockCRUDMockGet.Unlock()
-	return mock.GetFunc(id)
+	mock.lockGet.Unlock()
+	return mock.GetFunc(ctx, id)
 }
 
 // GetCalls gets all the calls that were made to Get.
 // Check the length with:
-//     len(mockedCRUD.GetCalls())
+//
+//	len(mockedCRUD.GetCalls())
 func (mock *CRUDMock) GetCalls() []struct {
-	ID string
+	Ctx context.Context
+	ID  string
 } {
 	var calls []struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}
-	lockCRUDMockGet.RLock()
+	mock.lockGet.RLock()
 	calls = mock.calls.Get
-	lockCRUDMockGet.RUnlock()
+	mock.lockGet.RUnlock()
+	return calls
+}
+
+// List calls ListFunc.
+func (mock *CRUDMock) List(ctx context.Context, filter map[string]interface{}) ([]interface{}, error) {
+	if mock.ListFunc == nil {
+		panic("CRUDMock.ListFunc: method is nil but CRUD.List was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Filter map[string]interface{}
+	}{
+		Ctx:    ctx,
+		Filter: filter,
+	}
+	mock.lockList.Lock()
+	mock.calls.List = append(mock.calls.List, callInfo)
+	mock.lockList.Unlock()
+	return mock.ListFunc(ctx, filter)
+}
+
+// ListCalls gets all the calls that were made to List.
+// Check the length with:
+//
+//	len(mockedCRUD.ListCalls())
+func (mock *CRUDMock) ListCalls() []struct {
+	Ctx    context.Context
+	Filter map[string]interface{}
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Filter map[string]interface{}
+	}
+	mock.lockList.RLock()
+	calls = mock.calls.List
+	mock.lockList.RUnlock()
 	return calls
 }
 
 // Update calls UpdateFunc.
-func (mock *CRUDMock) Update(dao interface{}) error {
+func (mock *CRUDMock) Update(ctx context.Context, dao interface{}) error {
 	if mock.UpdateFunc == nil {
 		panic("CRUDMock.UpdateFunc: method is nil but CRUD.Update was just called")
 	}
 	callInfo := struct {
+		Ctx context.Context
 		Dao interface{}
 	}{
+		Ctx: ctx,
 		Dao: dao,
 	}
-	lockCRUDMockUpdate.Lock()
+	mock.lockUpdate.Lock()
 	mock.calls.Update = append(mock.calls.Update, callInfo)
-	lockCRUDMockUpdate.Unlock()
-	return mock.UpdateFunc(dao)
+	mock.lockUpdate.Unlock()
+	return mock.UpdateFunc(ctx, dao)
 }
 
 // UpdateCalls gets all the calls that were made to Update.
 // Check the length with:
-//     len(mockedCRUD.UpdateCalls())
+//
+//	len(mockedCRUD.UpdateCalls())
 func (mock *CRUDMock) UpdateCalls() []struct {
+	Ctx context.Context
 	Dao interface{}
 } {
 	var calls []struct {
+		Ctx context.Context
 		Dao interface{}
 	}
-	lockCRUDMockUpdate.RLock()
+	mock.lockUpdate.RLock()
 	calls = mock.calls.Update
-	lockCRUDMockUpdate.RUnlock()
+	mock.lockUpdate.RUnlock()
 	return calls
 }
@@ -0,0 +1,45 @@
+package mock
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_APIClientList(t *testing.T) {
+	promotions := []interface{}{"DISC-10", "DISC-20", "EXPIRED-30"}
+
+	var crud CRUD = &APIClient{
+		ListFunc: func(ctx context.Context, filter map[string]interface{}) ([]interface{}, error) {
+			var result []interface{}
+			for _, promo := range promotions {
+				if filter["active"] == true && promo == "EXPIRED-30" {
+					continue
+				}
+				result = append(result, promo)
+			}
+			return result, nil
+		},
+	}
+
+	active, err := crud.List(context.Background(), map[string]interface{}{"active": true})
+	if err != nil {
+		t.Error("List must not return error, got:", err)
+	}
+	if len(active) != 2 {
+		t.Error("List should only return 2 active promotions, got:", active)
+	}
+}
+
+func Test_CRUDMockList(t *testing.T) {
+	var crud CRUD = &CRUDMock{
+		ListFunc: func(ctx context.Context, filter map[string]interface{}) ([]interface{}, error) {
+			return []interface{}{"DISC-10"}, nil
+		},
+	}
+
+	crud.List(context.Background(), map[string]interface{}{"active": true})
+	calls := crud.(*CRUDMock).ListCalls()
+	if len(calls) != 1 || calls[0].Filter["active"] != true {
+		t.Error("CRUDMock should track List calls, got:", calls)
+	}
+}
@@ -0,0 +1,90 @@
+package order
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL is how long a processed idempotency key is remembered
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotent result of a command, shared by all commands with the same key
+type idempotent struct {
+	done    chan struct{} // closed when the first command is processed
+	result  interface{}
+	err     error
+	expires time.Time
+}
+
+// idempotency in-memory store of processed keys, safe to use across workers
+type idempotency struct {
+	mux   sync.Mutex
+	ttl   time.Duration
+	keys  map[string]*idempotent
+	swept time.Time // last time the expired keys were swept
+}
+
+func newIdempotency(ttl time.Duration) *idempotency {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	return &idempotency{
+		ttl:   ttl,
+		keys:  map[string]*idempotent{},
+		swept: time.Now(),
+	}
+}
+
+// do process the command only once per key
+// Duplicate command waits for, and returns the result of the first command
+// Failed command forgets its key, so a retry is processed again
+func (store *idempotency) do(key string, process func() (interface{}, error)) (interface{}, error) {
+	if store == nil || key == "" {
+		return process()
+	}
+
+	now := time.Now()
+	store.mux.Lock()
+	store.sweep(now)
+	entry, exists := store.keys[key]
+	if exists && now.After(entry.expires) {
+		delete(store.keys, key)
+		exists = false
+	}
+	if !exists {
+		entry = &idempotent{done: make(chan struct{}), expires: now.Add(store.ttl)}
+		store.keys[key] = entry
+	}
+	store.mux.Unlock()
+
+	if exists {
+		<-entry.done
+		return entry.result, entry.err
+	}
+
+	entry.result, entry.err = process()
+	if entry.err != nil {
+		store.mux.Lock()
+		delete(store.keys, key)
+		store.mux.Unlock()
+	}
+
+	close(entry.done)
+	return entry.result, entry.err
+}
+
+// sweep expired keys at most once per ttl, must be called while holding the lock
+// Keys being looked up are expired lazily, the sweep only bounds keys never seen again
+func (store *idempotency) sweep(now time.Time) {
+	if now.Sub(store.swept) < store.ttl {
+		return
+	}
+
+	store.swept = now
+	for key, entry := range store.keys {
+		if now.After(entry.expires) {
+			delete(store.keys, key)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package order
+
+import (
+	"fmt"
+)
+
+// Field of a structured log entry
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger receives failed orders, e.g: to ship them into a logging pipeline
+type Logger interface {
+	Error(msg string, fields ...Field)
+}
+
+// stdout logger, which prints every entry into a single line
+type stdout struct{}
+
+func (stdout) Error(msg string, fields ...Field) {
+	args := []interface{}{msg}
+	for _, field := range fields {
+		args = append(args, field.Key+":", field.Value)
+	}
+
+	fmt.Println(args...)
+}
@@ -1,6 +1,7 @@
 package order
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -13,6 +14,9 @@ import (
 	"github.com/bastianrob/go-experiences/generator/order/pkg/command"
 )
 
+// maxProductFetch bounds the concurrent product lookups of a single order
+const maxProductFetch = 10
+
 // Services collection
 type Services struct {
 	Customer mock.CRUD
@@ -26,20 +30,31 @@ type Services struct {
 
 // Config for order service
 type Config struct {
-	Worker   int
-	Services Services
+	Worker         int
+	Services       Services
+	IdempotencyTTL time.Duration      // how long an idempotency key is remembered, defaults = 24h
+	Results        chan<- interface{} // optional channel on which created orders are sent
+	Failures       chan<- error       // optional channel on which failed orders are sent
+	Logger         Logger             // logs failed orders, defaults to stdout
 }
 
 // Root aggregate root of order
 type Root struct {
 	*actor.Actor
-	services Services
+	services    Services
+	idempotency *idempotency
+	logger      Logger
 }
 
 // NewAggregateRoot for order
 func NewAggregateRoot(cfg *Config) *Root {
 	root := &Root{
-		services: cfg.Services,
+		services:    cfg.Services,
+		idempotency: newIdempotency(cfg.IdempotencyTTL),
+		logger:      cfg.Logger,
+	}
+	if root.logger == nil {
+		root.logger = stdout{}
 	}
 
 	n := cfg.Worker
@@ -47,43 +62,69 @@ func NewAggregateRoot(cfg *Config) *Root {
 		n = 10
 	}
 
-	worker := &actor.Options{Worker: n}
+	worker := &actor.Options{Worker: n, ResultChannel: cfg.Results, FailChannel: cfg.Failures}
 	root.Actor = actor.New(root.processor, root.exception, worker)
 
 	return root
 }
 
 func (root *Root) processor(w int, a *actor.Actor, msg interface{}) (interface{}, error) {
+	msg = actor.Untrace(msg)
+	ctx, msg := command.Context(msg), command.Unwrap(msg)
 	if msg == nil {
 		return nil, errors.New("Order message is empty")
 	}
 
+	// 1. Converts message to command, a message of another type fails instead of panicking the worker
+	cmd, ok := msg.(*command.PlaceOrder)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", actor.ErrUnexpectedMessageType, msg)
+	}
+
+	// retried command with the same idempotency key returns the previously created order
+	return root.idempotency.do(cmd.IdempotencyKey, func() (interface{}, error) {
+		order, err := root.place(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		return order, nil
+	})
+}
+
+// place an order, then create its invoice and payment
+// Returns early with the context error once ctx is cancelled
+func (root *Root) place(ctx context.Context, cmd *command.PlaceOrder) (*dao.Order, error) {
 	var customer *dto.Customer
 	var merchant *dto.Merchant
 	var promo *dto.Promotion
 
-	// 1. Converts message to command
-	cmd := msg.(*command.PlaceOrder)
-
 	// 2. Fetch required information
 	// Uses goroutine because we all have verbose if err
-	errc := make(chan error)
+	errc := make(chan error, 1) // buffered, so the fetch does not leak when ctx is cancelled
 	go func(errc chan<- error) {
-		cust, err := root.services.Customer.Get(cmd.Customer)
+		cust, err := root.services.Customer.Get(ctx, cmd.Customer)
 		if err != nil {
 			errc <- err
 			return
 		}
 		customer = cust.(*dto.Customer)
+		if err = ctx.Err(); err != nil {
+			errc <- err
+			return
+		}
 
-		mcr, err := root.services.Merchant.Get(cmd.Merchant)
+		mcr, err := root.services.Merchant.Get(ctx, cmd.Merchant)
 		if err != nil {
 			errc <- err
 			return
 		}
 		merchant = mcr.(*dto.Merchant)
+		if err = ctx.Err(); err != nil {
+			errc <- err
+			return
+		}
 
-		prm, err := root.services.Promo.Get(cmd.Promo)
+		prm, err := root.services.Promo.Get(ctx, cmd.Promo)
 		if err != nil {
 			errc <- err
 			return
@@ -94,8 +135,13 @@ func (root *Root) processor(w int, a *actor.Actor, msg interface{}) (interface{}
 	}(errc)
 
 	// 3. Wait for fetch to complete and listen to any error occurred
-	if err := <-errc; err != nil {
-		return nil, err
+	select {
+	case err := <-errc:
+		if err != nil {
+			return nil, err
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
 	}
 
 	// 4. Get product details and calculate the total
@@ -108,13 +154,12 @@ func (root *Root) processor(w int, a *actor.Actor, msg interface{}) (interface{}
 		MerchantName: merchant.Name,
 		Items:        make([]*dao.OrderItem, len(cmd.Items)),
 	}
+	products, err := root.products(ctx, cmd.Items)
+	if err != nil {
+		return nil, err
+	}
 	for i, entry := range cmd.Items {
-		it, err := root.services.Product.Get(entry.ID)
-		if err != nil {
-			return nil, errors.New("Failed to get item with ID: " + entry.ID)
-		}
-
-		item := it.(*dto.Product)
+		item := products[i]
 		order.Items[i] = &dao.OrderItem{
 			ID:    item.ID,
 			Name:  item.Name,
@@ -125,10 +170,17 @@ func (root *Root) processor(w int, a *actor.Actor, msg interface{}) (interface{}
 	}
 
 	// 5. Persist the order data to database
-	err := root.services.Order.Create(order)
+	// every step afterward is compensated in reverse order, when the next step fails
+	// compensations do not use ctx, as they must still run after ctx is cancelled
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	var steps saga
+	err = root.services.Order.Create(ctx, order)
 	if err != nil {
 		return nil, errors.New("Failed to create a new order: " + err.Error())
 	}
+	steps.then(func() error { return root.services.Order.Delete(context.Background(), order) })
 
 	// 6. Create the invoice through API
 	discount := order.Total * promo.Discount / 100
@@ -140,10 +192,17 @@ func (root *Root) processor(w int, a *actor.Actor, msg interface{}) (interface{}
 		Discount: discount,
 		Total:    (order.Total - discount),
 	}
-	err = root.services.Invoice.Create(invoice)
+	if err = ctx.Err(); err != nil {
+		return nil, steps.compensate(err)
+	}
+	err = root.services.Invoice.Create(ctx, invoice)
 	if err != nil {
-		// TODO: Recovery strategy, delete the order? or flag it if you wish
-		return nil, errors.New("Failed to create a payment: " + err.Error())
+		// recover by deleting the order
+		return nil, steps.compensate(errors.New("Failed to create an invoice: " + err.Error()))
+	}
+	steps.then(func() error { return root.services.Invoice.Delete(context.Background(), invoice) })
+	if err = root.advance(ctx, &steps, order, dao.Invoiced); err != nil {
+		return nil, steps.compensate(err)
 	}
 
 	// 7. Make a payment through API call
@@ -152,15 +211,98 @@ func (root *Root) processor(w int, a *actor.Actor, msg interface{}) (interface{}
 		MethodID:  cmd.Payment,
 		Amount:    invoice.Total,
 	}
-	err = root.services.Payment.Create(payment)
+	if err = ctx.Err(); err != nil {
+		return nil, steps.compensate(err)
+	}
+	err = root.services.Payment.Create(ctx, payment)
 	if err != nil {
-		// TODO: Recovery strategy to both order and invoice
-		return nil, errors.New("Failed to create a payment: " + err.Error())
+		// recover by deleting the invoice, then the order
+		return nil, steps.compensate(errors.New("Failed to create a payment: " + err.Error()))
+	}
+	if err = root.advance(ctx, &steps, order, dao.Paid); err != nil {
+		return nil, steps.compensate(err)
 	}
 
 	return order, nil
 }
 
-func (root *Root) exception(w int, a *actor.Actor, err error) {
-	fmt.Println("Exception occurred at worker:", w, "with err:", err)
+// advance the order state and persist it
+// The persisted state is rolled back when a later step fails
+func (root *Root) advance(ctx context.Context, steps *saga, order *dao.Order, to dao.OrderState) error {
+	from := order.State
+	if err := order.Advance(to); err != nil {
+		return err
+	}
+
+	if err := root.services.Order.Update(ctx, order); err != nil {
+		order.State = from
+		return errors.New("Failed to update the order: " + err.Error())
+	}
+	steps.then(func() error {
+		order.State = from
+		return root.services.Order.Update(context.Background(), order)
+	})
+
+	return nil
+}
+
+// products fetch each line item's product concurrently, bounded by maxProductFetch
+// Results are collected preserving the line item order
+// No more product is fetched once ctx is cancelled
+func (root *Root) products(ctx context.Context, items []command.LineItem) ([]*dto.Product, error) {
+	products := make([]*dto.Product, len(items))
+	errc := make(chan error, len(items))
+	sem := make(chan struct{}, maxProductFetch)
+
+	fetching := 0
+	for i, entry := range items {
+		select {
+		case sem <- struct{}{}: // acquire a fetch slot
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		fetching++
+		go func(i int, id string) {
+			defer func() { <-sem }() // release the fetch slot
+
+			it, err := root.services.Product.Get(ctx, id)
+			if err != nil {
+				errc <- errors.New("Failed to get item with ID: " + id)
+				return
+			}
+
+			products[i] = it.(*dto.Product)
+			errc <- nil
+		}(i, entry.ID)
+	}
+
+	// wait for all fetch to complete, and keep the first error occurred
+	var err error
+	for ; fetching > 0; fetching-- {
+		if ferr := <-errc; ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+	if cerr := ctx.Err(); cerr != nil {
+		err = cerr
+	}
+
+	return products, err
+}
+
+func (root *Root) exception(w int, a *actor.Actor, msg interface{}, err error) {
+	fields := []Field{{"worker", w}, {"error", err}}
+	if id := actor.TraceID(msg); id != "" {
+		fields = append(fields, Field{"trace", id})
+	}
+
+	// the failed command is kept, so it can be inspected or retried
+	if cmd, ok := command.Unwrap(actor.Untrace(msg)).(*command.PlaceOrder); ok {
+		fields = append(fields, Field{"command", cmd}, Field{"customer", cmd.Customer}, Field{"merchant", cmd.Merchant})
+	}
+
+	root.logger.Error("Exception occurred", fields...)
 }
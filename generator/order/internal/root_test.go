@@ -1,8 +1,10 @@
 package order
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -17,7 +19,7 @@ import (
 // We'll do test
 func Test_OrderAsAggregateRoot(t *testing.T) {
 	customerAPIMock := &mock.APIClient{
-		GetFunc: func(id string) (interface{}, error) {
+		GetFunc: func(ctx context.Context, id string) (interface{}, error) {
 			time.Sleep(20 * time.Millisecond) // simulate 20ms latency
 			return &dto.Customer{
 				ID:   id,
@@ -26,7 +28,7 @@ func Test_OrderAsAggregateRoot(t *testing.T) {
 		},
 	}
 	merchantAPIMock := &mock.APIClient{
-		GetFunc: func(id string) (interface{}, error) {
+		GetFunc: func(ctx context.Context, id string) (interface{}, error) {
 			time.Sleep(20 * time.Millisecond) // simulate 20ms latency
 			return &dto.Merchant{
 				ID:   id,
@@ -35,7 +37,7 @@ func Test_OrderAsAggregateRoot(t *testing.T) {
 		},
 	}
 	promotionAPIMock := &mock.APIClient{
-		GetFunc: func(id string) (interface{}, error) {
+		GetFunc: func(ctx context.Context, id string) (interface{}, error) {
 			time.Sleep(20 * time.Millisecond) // simulate 20ms latency
 			return &dto.Promotion{
 				ID:       id,
@@ -45,7 +47,7 @@ func Test_OrderAsAggregateRoot(t *testing.T) {
 		},
 	}
 	invoiceAPIMock := &mock.APIClient{
-		CreateFunc: func(obj interface{}) error {
+		CreateFunc: func(ctx context.Context, obj interface{}) error {
 			time.Sleep(20 * time.Millisecond) // simulate 20ms latency
 			inv := obj.(*dto.Invoice)
 			inv.ID = "INV-001"
@@ -53,15 +55,18 @@ func Test_OrderAsAggregateRoot(t *testing.T) {
 		},
 	}
 	orderAPIMock := &mock.APIClient{
-		CreateFunc: func(obj interface{}) error {
+		CreateFunc: func(ctx context.Context, obj interface{}) error {
 			time.Sleep(20 * time.Millisecond) // simulate 20ms latency
 			inv := obj.(*dao.Order)
 			inv.ID = "INV-001"
 			return nil
 		},
+		UpdateFunc: func(ctx context.Context, obj interface{}) error {
+			return nil
+		},
 	}
 	paymentAPIMock := &mock.APIClient{
-		CreateFunc: func(obj interface{}) error {
+		CreateFunc: func(ctx context.Context, obj interface{}) error {
 			time.Sleep(20 * time.Millisecond) // simulate 20ms latency
 			pay := obj.(*dto.Payment)
 			pay.ID = "PMT-001"
@@ -69,7 +74,7 @@ func Test_OrderAsAggregateRoot(t *testing.T) {
 		},
 	}
 	productAPIMock := &mock.APIClient{
-		GetFunc: func(id string) (interface{}, error) {
+		GetFunc: func(ctx context.Context, id string) (interface{}, error) {
 			time.Sleep(20 * time.Millisecond) // simulate 20ms latency
 			switch id {
 			case "ITEM-001":
@@ -87,6 +92,10 @@ func Test_OrderAsAggregateRoot(t *testing.T) {
 		},
 	}
 
+	// results & failures keep tracks of how many order have we done processing
+	results := make(chan interface{})
+	failures := make(chan error, 100)
+
 	// root is order actor which acts as an aggregate root
 	// have by default 10 workers
 	root := NewAggregateRoot(&Config{
@@ -100,22 +109,10 @@ func Test_OrderAsAggregateRoot(t *testing.T) {
 			Product:  productAPIMock,
 			Promo:    promotionAPIMock,
 		},
+		Results:  results,
+		Failures: failures,
 	})
 
-	// waiter is an actor which hears from root and keep tracks of how many order have we done processing
-	wg := &sync.WaitGroup{}
-	wg.Add(100)
-	waiter := actor.New(
-		func(w int, a *actor.Actor, message interface{}) (interface{}, error) {
-			wg.Done()
-			return nil, nil
-		},
-		func(w int, a *actor.Actor, err error) {
-			wg.Done()
-		},
-		&actor.Options{Worker: 10},
-	)
-
 	// clock in to check how long we're porcessing 100 command
 	start := time.Now()
 
@@ -138,9 +135,14 @@ func Test_OrderAsAggregateRoot(t *testing.T) {
 	}
 	root.Queue(orders...)
 
-	actor.Direct(root.Actor, waiter)
 	fmt.Println("We are waiting")
-	wg.Wait()
+	for i := 0; i < 100; i++ {
+		select {
+		case <-results:
+		case err := <-failures:
+			t.Error("Order should not fail, got:", err)
+		}
+	}
 
 	// we have at least 7 fake services and each takes simulated 20ms to complete
 	// so total time it takes to complete 100 command * 140ms = 14sec
@@ -153,3 +155,407 @@ func Test_OrderAsAggregateRoot(t *testing.T) {
 
 	fmt.Println("Duration:", dur)
 }
+
+// mockServices without latency, which succeed unless overridden
+func mockServices() (Services, map[string]*mock.CRUDMock) {
+	get := func(dto interface{}) func(ctx context.Context, id string) (interface{}, error) {
+		return func(ctx context.Context, id string) (interface{}, error) {
+			return dto, nil
+		}
+	}
+	ok := func(ctx context.Context, dao interface{}) error { return nil }
+
+	mocks := map[string]*mock.CRUDMock{
+		"customer": {GetFunc: get(&dto.Customer{ID: "CUST-001"})},
+		"merchant": {GetFunc: get(&dto.Merchant{ID: "MRCN-001"})},
+		"promo":    {GetFunc: get(&dto.Promotion{ID: "DISC-10", Discount: 10})},
+		"product":  {GetFunc: get(&dto.Product{ID: "ITEM-001", Price: 100})},
+		"order":    {CreateFunc: ok, UpdateFunc: ok, DeleteFunc: ok},
+		"invoice":  {CreateFunc: ok, DeleteFunc: ok},
+		"payment":  {CreateFunc: ok, DeleteFunc: ok},
+	}
+
+	return Services{
+		Customer: mocks["customer"],
+		Merchant: mocks["merchant"],
+		Promo:    mocks["promo"],
+		Product:  mocks["product"],
+		Order:    mocks["order"],
+		Invoice:  mocks["invoice"],
+		Payment:  mocks["payment"],
+	}, mocks
+}
+
+func Test_OrderCompensation(t *testing.T) {
+	fail := func(ctx context.Context, dao interface{}) error { return errors.New("503") }
+	cmd := &command.PlaceOrder{
+		Customer: "CUST-001",
+		Merchant: "MRCN-001",
+		Payment:  "CARD-001",
+		Promo:    "DISC-10",
+		Items:    []command.LineItem{{ID: "ITEM-001", Qty: 1}},
+	}
+
+	tests := []struct {
+		name           string
+		failing        string
+		orderDeleted   int
+		invoiceDeleted int
+	}{
+		{name: "No failure", failing: "", orderDeleted: 0, invoiceDeleted: 0},
+		{name: "Invoice fails", failing: "invoice", orderDeleted: 1, invoiceDeleted: 0},
+		{name: "Payment fails", failing: "payment", orderDeleted: 1, invoiceDeleted: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			services, mocks := mockServices()
+			if tt.failing != "" {
+				mocks[tt.failing].CreateFunc = fail
+			}
+
+			root := &Root{services: services}
+			_, err := root.processor(1, nil, cmd)
+			if (err != nil) != (tt.failing != "") {
+				t.Error("Processor error should only happen when a step fails, got:", err)
+			}
+			if n := len(mocks["order"].DeleteCalls()); n != tt.orderDeleted {
+				t.Error("Order should be deleted", tt.orderDeleted, "times, got:", n)
+			}
+			if n := len(mocks["invoice"].DeleteCalls()); n != tt.invoiceDeleted {
+				t.Error("Invoice should be deleted", tt.invoiceDeleted, "times, got:", n)
+			}
+		})
+	}
+}
+
+func Test_OrderCompensationOrder(t *testing.T) {
+	services, mocks := mockServices()
+	var undone []string
+	mocks["payment"].CreateFunc = func(ctx context.Context, dao interface{}) error { return errors.New("503") }
+	mocks["invoice"].DeleteFunc = func(ctx context.Context, dao interface{}) error {
+		undone = append(undone, "invoice")
+		return nil
+	}
+	mocks["order"].DeleteFunc = func(ctx context.Context, dao interface{}) error {
+		undone = append(undone, "order")
+		return errors.New("order is locked")
+	}
+
+	root := &Root{services: services}
+	_, err := root.processor(1, nil, &command.PlaceOrder{Items: []command.LineItem{{ID: "ITEM-001", Qty: 1}}})
+	if len(undone) != 2 || undone[0] != "invoice" || undone[1] != "order" {
+		t.Error("Compensation should run in reverse order, got:", undone)
+	}
+	if err == nil || !strings.Contains(err.Error(), "order is locked") {
+		t.Error("Failed compensation should be reported, got:", err)
+	}
+}
+
+func Test_OrderProductsConcurrently(t *testing.T) {
+	services, mocks := mockServices()
+	mocks["product"].GetFunc = func(ctx context.Context, id string) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond) // simulate 20ms latency
+		return &dto.Product{ID: id, Price: 100}, nil
+	}
+
+	root := &Root{services: services}
+	cmd := &command.PlaceOrder{}
+	for i := 0; i < maxProductFetch; i++ {
+		cmd.Items = append(cmd.Items, command.LineItem{ID: fmt.Sprintf("ITEM-%03d", i), Qty: 1})
+	}
+
+	start := time.Now()
+	result, err := root.processor(1, nil, cmd)
+	dur := time.Since(start)
+	if err != nil {
+		t.Fatal("Processor must not return error, got:", err)
+	}
+
+	// 10 items * 20ms = 200ms when fetched serially, but near one 20ms round trip when concurrent
+	if dur >= 100*time.Millisecond {
+		t.Error("Product lookups should be concurrent, took:", dur)
+	}
+
+	order := result.(*dao.Order)
+	for i, item := range order.Items {
+		if item.ID != cmd.Items[i].ID {
+			t.Error("Order items should preserve line item order, got:", item.ID, "at", i)
+		}
+	}
+	if order.Total != maxProductFetch*100 {
+		t.Error("Order total should be", maxProductFetch*100, "got:", order.Total)
+	}
+}
+
+func Test_OrderState(t *testing.T) {
+	services, _ := mockServices()
+	root := &Root{services: services}
+
+	result, err := root.processor(1, nil, &command.PlaceOrder{Items: []command.LineItem{{ID: "ITEM-001", Qty: 1}}})
+	if err != nil {
+		t.Fatal("Processor must not return error, got:", err)
+	}
+	if state := result.(*dao.Order).State; state != dao.Paid {
+		t.Error("Order should be advanced to paid, got:", state)
+	}
+}
+
+func Test_OrderStatePersisted(t *testing.T) {
+	services, mocks := mockServices()
+	var states []dao.OrderState
+	mocks["order"].UpdateFunc = func(ctx context.Context, obj interface{}) error {
+		states = append(states, obj.(*dao.Order).State)
+		return nil
+	}
+
+	root := &Root{services: services}
+	if _, err := root.processor(1, nil, &command.PlaceOrder{Items: []command.LineItem{{ID: "ITEM-001", Qty: 1}}}); err != nil {
+		t.Fatal("Processor must not return error, got:", err)
+	}
+	if n := len(mocks["order"].UpdateCalls()); n != 2 || states[0] != dao.Invoiced || states[1] != dao.Paid {
+		t.Error("Order should be updated as invoiced, then paid, got:", states)
+	}
+}
+
+func Test_OrderStateRolledBack(t *testing.T) {
+	services, mocks := mockServices()
+	var states []dao.OrderState
+	mocks["payment"].CreateFunc = func(ctx context.Context, dao interface{}) error { return errors.New("503") }
+	mocks["order"].UpdateFunc = func(ctx context.Context, obj interface{}) error {
+		states = append(states, obj.(*dao.Order).State)
+		return nil
+	}
+
+	root := &Root{services: services}
+	if _, err := root.processor(1, nil, &command.PlaceOrder{Items: []command.LineItem{{ID: "ITEM-001", Qty: 1}}}); err == nil {
+		t.Fatal("Processor should fail when payment fails")
+	}
+	if len(states) != 2 || states[0] != dao.Invoiced || states[1] != dao.New {
+		t.Error("Invoiced state should be rolled back to new, got:", states)
+	}
+}
+
+func Test_OrderIdempotency(t *testing.T) {
+	services, mocks := mockServices()
+	mocks["order"].CreateFunc = func(ctx context.Context, obj interface{}) error {
+		time.Sleep(20 * time.Millisecond) // simulate 20ms latency
+		return nil
+	}
+
+	root := NewAggregateRoot(&Config{Worker: 5, Services: services})
+
+	// collector is an actor which collects the created orders
+	results := make(chan interface{}, 2)
+	collector := actor.New(
+		func(w int, a *actor.Actor, message interface{}) (interface{}, error) {
+			results <- message
+			return nil, nil
+		},
+		func(w int, a *actor.Actor, message interface{}, err error) {
+			results <- err
+		},
+		&actor.Options{Worker: 1},
+	)
+	actor.Direct(root.Actor, collector)
+
+	// the same command is retried while the first one is still processing
+	place := func() *command.PlaceOrder {
+		return &command.PlaceOrder{
+			IdempotencyKey: "ORDER-KEY-001",
+			Items:          []command.LineItem{{ID: "ITEM-001", Qty: 1}},
+		}
+	}
+	root.Queue(place(), place())
+
+	first, second := <-results, <-results
+	if n := len(mocks["order"].CreateCalls()); n != 1 {
+		t.Error("Only one order should be created, got:", n)
+	}
+	if _, ok := first.(*dao.Order); !ok || first != second {
+		t.Error("Duplicate command should return the previously created order, got:", first, second)
+	}
+}
+
+func Test_OrderIdempotencyRetryFailure(t *testing.T) {
+	services, mocks := mockServices()
+	fail := true
+	mocks["payment"].CreateFunc = func(ctx context.Context, obj interface{}) error {
+		if fail {
+			return errors.New("503")
+		}
+		return nil
+	}
+
+	root := &Root{services: services, idempotency: newIdempotency(time.Hour)}
+	cmd := &command.PlaceOrder{IdempotencyKey: "ORDER-KEY-002", Items: []command.LineItem{{ID: "ITEM-001", Qty: 1}}}
+
+	if _, err := root.processor(1, nil, cmd); err == nil {
+		t.Fatal("First command should fail")
+	}
+
+	fail = false
+	if _, err := root.processor(1, nil, cmd); err != nil {
+		t.Error("Retry of a failed command should be processed again, got:", err)
+	}
+	if n := len(mocks["order"].CreateCalls()); n != 2 {
+		t.Error("Retry of a failed command should create the order again, got:", n)
+	}
+}
+
+func Test_OrderIdempotencyExpired(t *testing.T) {
+	store := newIdempotency(10 * time.Millisecond)
+	calls := 0
+	process := func() (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+
+	store.do("ORDER-KEY-003", process)
+	time.Sleep(20 * time.Millisecond)
+	if result, _ := store.do("ORDER-KEY-003", process); result != 2 {
+		t.Error("Expired key should be processed again, got:", result)
+	}
+	if n := len(store.keys); n != 1 {
+		t.Error("Expired key should be replaced, got keys:", n)
+	}
+}
+
+func Test_OrderCancelled(t *testing.T) {
+	services, mocks := mockServices()
+	slow := func(dto interface{}) func(ctx context.Context, id string) (interface{}, error) {
+		return func(ctx context.Context, id string) (interface{}, error) {
+			select {
+			case <-time.After(20 * time.Millisecond): // simulate 20ms latency
+				return dto, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	mocks["customer"].GetFunc = slow(&dto.Customer{ID: "CUST-001"})
+	mocks["merchant"].GetFunc = slow(&dto.Merchant{ID: "MRCN-001"})
+	mocks["promo"].GetFunc = slow(&dto.Promotion{ID: "DISC-10"})
+	mocks["product"].GetFunc = slow(&dto.Product{ID: "ITEM-001"})
+
+	// cancelled while fetching the merchant
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	root := &Root{services: services}
+	cmd := command.WithContext(ctx, &command.PlaceOrder{Items: []command.LineItem{{ID: "ITEM-001", Qty: 1}}})
+
+	start := time.Now()
+	_, err := root.processor(1, nil, cmd)
+	dur := time.Since(start)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("Cancelled command should return the context error, got:", err)
+	}
+	// customer, merchant, promo and product take 80ms when not cancelled
+	if dur >= 60*time.Millisecond {
+		t.Error("Cancelled command should return early, took:", dur)
+	}
+	if n := len(mocks["promo"].GetCalls()); n != 0 {
+		t.Error("Promo should not be fetched after cancelled, got:", n)
+	}
+	if n := len(mocks["order"].CreateCalls()); n != 0 {
+		t.Error("Order should not be created after cancelled, got:", n)
+	}
+}
+
+// capture logger, which keeps every logged entry
+type capture struct {
+	mux     sync.Mutex
+	entries []map[string]interface{}
+}
+
+func (c *capture) Error(msg string, fields ...Field) {
+	entry := map[string]interface{}{"msg": msg}
+	for _, field := range fields {
+		entry[field.Key] = field.Value
+	}
+
+	c.mux.Lock()
+	c.entries = append(c.entries, entry)
+	c.mux.Unlock()
+}
+
+func Test_OrderLogger(t *testing.T) {
+	services, mocks := mockServices()
+	mocks["payment"].CreateFunc = func(ctx context.Context, obj interface{}) error {
+		return errors.New("503")
+	}
+
+	logger := &capture{}
+	failures := make(chan error, 1)
+	root := NewAggregateRoot(&Config{Worker: 1, Services: services, Logger: logger, Failures: failures})
+	defer root.Stop()
+
+	cmd := &command.PlaceOrder{
+		Customer: "CUST-001",
+		Merchant: "MRCN-001",
+		Items:    []command.LineItem{{ID: "ITEM-001", Qty: 1}},
+	}
+	root.Queue(actor.WithTrace("TRACE-001", cmd))
+
+	select {
+	case <-failures:
+	case <-time.After(1 * time.Second):
+		t.Fatal("Order should fail")
+	}
+
+	logger.mux.Lock()
+	defer logger.mux.Unlock()
+	if len(logger.entries) != 1 {
+		t.Fatal("Failed order should be logged once, got:", len(logger.entries))
+	}
+	entry := logger.entries[0]
+	if entry["customer"] != "CUST-001" || entry["merchant"] != "MRCN-001" {
+		t.Error("Failed order should be logged with its customer and merchant, got:", entry)
+	}
+	if entry["command"] != cmd {
+		t.Error("Failed command should be recoverable from the log entry, got:", entry["command"])
+	}
+	if err, _ := entry["error"].(error); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Error("Failed order should be logged with its error, got:", entry["error"])
+	}
+	if entry["trace"] != "TRACE-001" {
+		t.Error("Failed order should be logged with its trace id, got:", entry["trace"])
+	}
+}
+
+func Test_OrderUnexpectedMessage(t *testing.T) {
+	services, _ := mockServices()
+	logger := &capture{}
+	results := make(chan interface{}, 1)
+	failures := make(chan error, 1)
+	root := NewAggregateRoot(&Config{Worker: 1, Services: services, Logger: logger, Results: results, Failures: failures})
+	defer root.Stop()
+
+	root.Queue("not an order")
+	select {
+	case err := <-failures:
+		if !errors.Is(err, actor.ErrUnexpectedMessageType) {
+			t.Error("Unexpected message should fail with ErrUnexpectedMessageType, got:", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Unexpected message should go through the exception path")
+	}
+
+	logger.mux.Lock()
+	logged := len(logger.entries)
+	logger.mux.Unlock()
+	if logged != 1 {
+		t.Error("Exception handler should log the unexpected message once, got:", logged)
+	}
+
+	// the only worker survives, and keeps processing orders
+	root.Queue(&command.PlaceOrder{Items: []command.LineItem{{ID: "ITEM-001", Qty: 1}}})
+	select {
+	case <-results:
+	case err := <-failures:
+		t.Error("Order should not fail, got:", err)
+	case <-time.After(1 * time.Second):
+		t.Fatal("Worker should keep processing after an unexpected message")
+	}
+}
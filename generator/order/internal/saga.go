@@ -0,0 +1,29 @@
+package order
+
+import (
+	"fmt"
+)
+
+// compensation undo a step which have been completed
+type compensation func() error
+
+// saga is an ordered list of compensations of each completed step
+// which is executed in reverse order when the next step fails
+type saga []compensation
+
+// then registers the compensation of a completed step
+func (s *saga) then(undo compensation) {
+	*s = append(*s, undo)
+}
+
+// compensate executes all compensations in reverse order
+// Returns the original err, annotated with any failed compensation
+func (s saga) compensate(err error) error {
+	for i := len(s) - 1; i >= 0; i-- {
+		if uerr := s[i](); uerr != nil {
+			err = fmt.Errorf("%w, and failed to compensate: %v", err, uerr)
+		}
+	}
+
+	return err
+}
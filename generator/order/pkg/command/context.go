@@ -0,0 +1,35 @@
+package command
+
+import (
+	"context"
+)
+
+// withContext wraps a command with the context which cancels its processing
+type withContext struct {
+	ctx     context.Context
+	command interface{}
+}
+
+// WithContext wraps a command with ctx, e.g: before queueing it into the aggregate root
+// The context travels next to the command instead of inside it, as a queued message is the only thing an actor receives
+func WithContext(ctx context.Context, command interface{}) interface{} {
+	return &withContext{ctx: ctx, command: command}
+}
+
+// Context get the context of a wrapped command, or context.Background() when message is not wrapped
+func Context(message interface{}) context.Context {
+	if wc, ok := message.(*withContext); ok && wc.ctx != nil {
+		return wc.ctx
+	}
+
+	return context.Background()
+}
+
+// Unwrap get the original command of a message wrapped with context
+func Unwrap(message interface{}) interface{} {
+	if wc, ok := message.(*withContext); ok {
+		return wc.command
+	}
+
+	return message
+}
@@ -8,9 +8,10 @@ type LineItem struct {
 
 // PlaceOrder command
 type PlaceOrder struct {
-	Customer string
-	Merchant string
-	Payment  string
-	Promo    string
-	Items    []LineItem
+	IdempotencyKey string // retried command with the same key is only processed once
+	Customer       string
+	Merchant       string
+	Payment        string
+	Promo          string
+	Items          []LineItem
 }
@@ -1,6 +1,10 @@
 package dao
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // OrderState ...
 type OrderState string
@@ -13,6 +17,26 @@ const (
 	Expired  = OrderState("expired")
 )
 
+// ErrInvalidTransition when order state is advanced outside of the legal graph
+var ErrInvalidTransition = errors.New("Order state transition is not allowed")
+
+// transitions legal graph of order state
+var transitions = map[OrderState][]OrderState{
+	New:      {Invoiced, Expired},
+	Invoiced: {Paid, Expired},
+}
+
+// Transition validates whether order state can be advanced from one state to another
+func Transition(from, to OrderState) error {
+	for _, next := range transitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: from '%s' to '%s'", ErrInvalidTransition, from, to)
+}
+
 // OrderItem DAO
 type OrderItem struct {
 	ID    string
@@ -33,3 +57,13 @@ type Order struct {
 	Items        []*OrderItem
 	Total        int
 }
+
+// Advance order state, only when the transition is legal
+func (order *Order) Advance(to OrderState) error {
+	if err := Transition(order.State, to); err != nil {
+		return err
+	}
+
+	order.State = to
+	return nil
+}
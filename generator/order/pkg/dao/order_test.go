@@ -0,0 +1,49 @@
+package dao
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Transition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    OrderState
+		to      OrderState
+		allowed bool
+	}{
+		{name: "New to Invoiced", from: New, to: Invoiced, allowed: true},
+		{name: "New to Expired", from: New, to: Expired, allowed: true},
+		{name: "Invoiced to Paid", from: Invoiced, to: Paid, allowed: true},
+		{name: "Invoiced to Expired", from: Invoiced, to: Expired, allowed: true},
+		{name: "New to Paid", from: New, to: Paid, allowed: false},
+		{name: "New to New", from: New, to: New, allowed: false},
+		{name: "Invoiced to New", from: Invoiced, to: New, allowed: false},
+		{name: "Paid to Expired", from: Paid, to: Expired, allowed: false},
+		{name: "Paid to Invoiced", from: Paid, to: Invoiced, allowed: false},
+		{name: "Expired to Paid", from: Expired, to: Paid, allowed: false},
+		{name: "Unknown to New", from: OrderState("unknown"), to: New, allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Transition(tt.from, tt.to)
+			if tt.allowed && err != nil {
+				t.Error("Transition should be allowed, got:", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrInvalidTransition) {
+				t.Error("Transition should return ErrInvalidTransition, got:", err)
+			}
+		})
+	}
+}
+
+func Test_OrderAdvance(t *testing.T) {
+	order := &Order{State: New}
+	if err := order.Advance(Paid); err == nil || order.State != New {
+		t.Error("Illegal advance must not change order state, got:", order.State)
+	}
+	if err := order.Advance(Invoiced); err != nil || order.State != Invoiced {
+		t.Error("Legal advance should change order state, got:", order.State, err)
+	}
+}
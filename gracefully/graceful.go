@@ -2,35 +2,169 @@ package gracefully
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
 
-// Serve HTTP gracefuly
-func Serve(listenAndServe func() error, teardown func(context.Context) error) error {
-	term := make(chan os.Signal) // OS termination signal
-	fail := make(chan error)     // Teardown failure signal
+// DefaultTimeout for all teardown process to complete
+const DefaultTimeout = 30 * time.Second
 
+// DefaultSignals listened for termination
+var DefaultSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+
+// Config of graceful shutdown
+// Zero value uses DefaultTimeout and DefaultSignals, without drain delay
+type Config struct {
+	Timeout time.Duration // all teardown process must complete within timeout
+	Signals []os.Signal   // termination signals to listen for
+
+	// Ready is an optional readiness flag, atomically set to 0 on signal so readiness probe starts failing
+	// DrainDelay is how long to keep serving afterward, so load balancers stop routing traffic before teardown
+	Ready      *int32
+	DrainDelay time.Duration
+}
+
+// withDefaults fills the zero fields with defaults
+func (cfg Config) withDefaults() Config {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = DefaultSignals
+	}
+
+	return cfg
+}
+
+// ServerSpec pairs a server listenAndServe with its teardown process
+type ServerSpec struct {
+	ListenAndServe func() error
+	Teardown       func(context.Context) error
+}
+
+// Serve HTTP gracefuly, with 30s teardown timeout on SIGINT or SIGTERM
+// Optional preShutdown hooks run in order, e.g. to flip readiness flag or deregister from service discovery
+// Shutdown ordering: signal -> preShutdown -> teardown(ctx)
+func Serve(listenAndServe func() error, teardown func(context.Context) error, preShutdown ...func()) error {
+	_, err := ServeSignal(listenAndServe, teardown, preShutdown...)
+	return err
+}
+
+// ServeSignal serve HTTP gracefuly, and returns the signal which triggered the shutdown
+// The returned signal is nil when listenAndServe fails before any signal is received
+func ServeSignal(listenAndServe func() error, teardown func(context.Context) error, preShutdown ...func()) (os.Signal, error) {
+	return serve(Config{}, listenAndServe, teardown, preShutdown...)
+}
+
+// ServeWithConfig serve HTTP gracefuly, with configurable teardown timeout, termination signals, and drain delay
+// Shutdown ordering: signal -> not ready -> preShutdown -> drain delay -> teardown(ctx)
+func ServeWithConfig(cfg Config, listenAndServe func() error, teardown func(context.Context) error, preShutdown ...func()) error {
+	_, err := serve(cfg, listenAndServe, teardown, preShutdown...)
+	return err
+}
+
+// serve gracefuly and returns the caught termination signal
+func serve(cfg Config, listenAndServe func() error, teardown func(context.Context) error, preShutdown ...func()) (os.Signal, error) {
+	cfg = cfg.withDefaults()
+	term := make(chan os.Signal, 1) // OS termination signal
+	fail := make(chan error)        // Teardown failure signal
+
+	// listen to termination signal before serving, so no signal is missed
+	signal.Notify(term, cfg.Signals...)
+	defer signal.Stop(term)
+
+	var caught os.Signal
 	go func() {
-		signal.Notify(term, syscall.SIGINT, syscall.SIGTERM)
-		<-term // waits for termination signal
+		caught = <-term // waits for termination signal
+
+		// deregistration phase, server keeps serving while it is marked as not ready
+		if cfg.Ready != nil {
+			atomic.StoreInt32(cfg.Ready, 0)
+		}
+
+		// pre shutdown hooks run before the teardown timeout starts
+		for _, hook := range preShutdown {
+			hook()
+		}
 
-		// context with 30s timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		// requests routed during the deregistration gap are still served
+		time.Sleep(cfg.DrainDelay)
+
+		// context with configured timeout
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
 		defer cancel()
 
-		// all teardown process must complete within 30 seconds
+		// all teardown process must complete within timeout
 		fail <- teardown(ctx)
 	}()
 
 	// listenAndServe blocks our code from exit, but will produce ErrServerClosed when stopped
 	if err := listenAndServe(); err != nil && err != http.ErrServerClosed {
-		return err
+		return nil, err
+	}
+
+	// after server gracefully stopped, code proceeds here and waits for any error produced by teardown() process
+	err := <-fail
+	return caught, err
+}
+
+// ServeAll serve multiple HTTP servers gracefuly, e.g. an API server plus a metrics server
+// On termination signal, or when any server fails to serve, all teardowns run concurrently within the shared 30s timeout
+// Returns the first error, the others are logged
+func ServeAll(servers []ServerSpec) error {
+	cfg := Config{}.withDefaults()
+	term := make(chan os.Signal, 1)        // OS termination signal
+	fail := make(chan error, len(servers)) // Serve failure signal
+
+	signal.Notify(term, cfg.Signals...)
+	defer signal.Stop(term)
+
+	for _, srv := range servers {
+		go func(listenAndServe func() error) {
+			if err := listenAndServe(); err != nil && err != http.ErrServerClosed {
+				fail <- err
+			}
+		}(srv.ListenAndServe)
+	}
+
+	var errs []error
+	select {
+	case <-term: // waits for termination signal
+	case err := <-fail: // or for any server to fail, so the others are torn down
+		errs = append(errs, err)
 	}
 
-	// after server gracefully stopped, code proceeds here and waits for any error produced by teardown() process @ line 26
-	return <-fail
+	// context with timeout, shared by all teardowns
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	mux := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, srv := range servers {
+		wg.Add(1)
+		go func(teardown func(context.Context) error) {
+			defer wg.Done()
+			if err := teardown(ctx); err != nil {
+				mux.Lock()
+				errs = append(errs, err)
+				mux.Unlock()
+			}
+		}(srv.Teardown)
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for _, err := range errs[1:] {
+		log.Println("gracefully:", err)
+	}
+	return errs[0]
 }
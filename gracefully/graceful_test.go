@@ -0,0 +1,184 @@
+package gracefully
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// terminate sends SIGTERM to the running test process after a delay
+func terminate(t *testing.T, after time.Duration) {
+	time.AfterFunc(after, func() {
+		if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+			t.Error("Failed to send SIGTERM:", err)
+		}
+	})
+}
+
+func Test_Serve(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0"}
+	var tornDown int32
+
+	terminate(t, 100*time.Millisecond)
+	err := Serve(srv.ListenAndServe, func(ctx context.Context) error {
+		atomic.AddInt32(&tornDown, 1)
+		return srv.Shutdown(ctx)
+	})
+
+	if err != nil {
+		t.Error("Serve should not return error, got:", err)
+	}
+	if atomic.LoadInt32(&tornDown) != 1 {
+		t.Error("Teardown should run once")
+	}
+}
+
+func Test_ServeAll(t *testing.T) {
+	api := &http.Server{Addr: "127.0.0.1:0"}
+	metrics := &http.Server{Addr: "127.0.0.1:0"}
+	var tornDown int32
+
+	teardown := func(srv *http.Server) func(context.Context) error {
+		return func(ctx context.Context) error {
+			atomic.AddInt32(&tornDown, 1)
+			return srv.Shutdown(ctx)
+		}
+	}
+
+	terminate(t, 100*time.Millisecond)
+	err := ServeAll([]ServerSpec{
+		{ListenAndServe: api.ListenAndServe, Teardown: teardown(api)},
+		{ListenAndServe: metrics.ListenAndServe, Teardown: teardown(metrics)},
+	})
+
+	if err != nil {
+		t.Error("ServeAll should not return error, got:", err)
+	}
+	if atomic.LoadInt32(&tornDown) != 2 {
+		t.Error("Both teardowns should run, got:", atomic.LoadInt32(&tornDown))
+	}
+}
+
+func Test_ServeAll_Failure(t *testing.T) {
+	errListen := errors.New("listen failure")
+	api := &http.Server{Addr: "127.0.0.1:0"}
+	var tornDown int32
+
+	err := ServeAll([]ServerSpec{
+		{ListenAndServe: api.ListenAndServe, Teardown: func(ctx context.Context) error {
+			atomic.AddInt32(&tornDown, 1)
+			return api.Shutdown(ctx)
+		}},
+		{ListenAndServe: func() error { return errListen }, Teardown: func(ctx context.Context) error {
+			atomic.AddInt32(&tornDown, 1)
+			return nil
+		}},
+	})
+
+	if err != errListen {
+		t.Error("ServeAll should return the serve failure, got:", err)
+	}
+	if atomic.LoadInt32(&tornDown) != 2 {
+		t.Error("Both teardowns should run, got:", atomic.LoadInt32(&tornDown))
+	}
+}
+
+func Test_ServeWithConfig_Timeout(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0"}
+	cfg := Config{Timeout: 50 * time.Millisecond, Signals: []os.Signal{syscall.SIGUSR1}}
+
+	time.AfterFunc(100*time.Millisecond, func() {
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	})
+	err := ServeWithConfig(cfg, srv.ListenAndServe, func(ctx context.Context) error {
+		srv.Shutdown(ctx)
+
+		// slow teardown which exceeds the timeout
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
+			return nil
+		}
+	})
+
+	if err != context.DeadlineExceeded {
+		t.Error("Teardown exceeding timeout should have its context cancelled, got:", err)
+	}
+}
+
+func Test_Serve_PreShutdown(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0"}
+	var order []string
+
+	terminate(t, 100*time.Millisecond)
+	err := Serve(srv.ListenAndServe, func(ctx context.Context) error {
+		order = append(order, "teardown")
+		return srv.Shutdown(ctx)
+	}, func() {
+		order = append(order, "preShutdown")
+	})
+
+	if err != nil {
+		t.Error("Serve should not return error, got:", err)
+	}
+	if len(order) != 2 || order[0] != "preShutdown" || order[1] != "teardown" {
+		t.Error("preShutdown should run before teardown, got:", order)
+	}
+}
+
+func Test_ServeSignal(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0"}
+
+	time.AfterFunc(100*time.Millisecond, func() {
+		syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+	})
+	sig, err := ServeSignal(srv.ListenAndServe, srv.Shutdown)
+
+	if err != nil {
+		t.Error("ServeSignal should not return error, got:", err)
+	}
+	if sig != syscall.SIGINT {
+		t.Error("ServeSignal should return SIGINT, got:", sig)
+	}
+}
+
+func Test_ServeWithConfig_DrainDelay(t *testing.T) {
+	srv := &http.Server{Addr: "127.0.0.1:0"}
+	ready := int32(1)
+	delay := 200 * time.Millisecond
+	cfg := Config{Ready: &ready, DrainDelay: delay}
+
+	var unready time.Time
+	signalled := make(chan time.Time, 1)
+	time.AfterFunc(100*time.Millisecond, func() {
+		signalled <- time.Now()
+		syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	})
+	err := ServeWithConfig(cfg, srv.ListenAndServe, func(ctx context.Context) error {
+		if atomic.LoadInt32(&ready) != 0 {
+			t.Error("Readiness flag should be flipped before teardown")
+		}
+		if elapsed := time.Since(unready); elapsed < delay {
+			t.Error("Drain delay should elapse before teardown, got:", elapsed)
+		}
+		return srv.Shutdown(ctx)
+	}, func() {
+		unready = time.Now()
+		if atomic.LoadInt32(&ready) != 0 {
+			t.Error("Readiness flag should be flipped before preShutdown")
+		}
+	})
+
+	if err != nil {
+		t.Error("ServeWithConfig should not return error, got:", err)
+	}
+	if unready.Sub(<-signalled) >= delay {
+		t.Error("Readiness flag should be flipped right after signal, not after drain delay")
+	}
+}
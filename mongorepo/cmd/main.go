@@ -6,7 +6,7 @@ import (
 	"os"
 
 	"github.com/bastianrob/go-experiences/mongorepo/pkg/models"
-	"github.com/bastianrob/go-experiences/mongorepo/pkg/mongorepo"
+	"github.com/bastianrob/go-experiences/mongorepo/pkg/repo"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -21,18 +21,10 @@ func main() {
 	mongodb := mongocl.Database("dbtest")
 
 	// personRepo, ngarah ke collection 'person'
-	personRepo := mongorepo.New(
-		mongodb.Collection("person"),
-		func() interface{} {
-			return &models.Person{}
-		})
+	personRepo := repo.New[models.Person](mongodb.Collection("person"))
 
 	// enemyRepo, ngarah ke collection 'enemy'
-	enemyRepo := mongorepo.New(
-		mongodb.Collection("enemy"),
-		func() interface{} {
-			return &models.Enemy{}
-		})
+	enemyRepo := repo.New[models.Enemy](mongodb.Collection("enemy"))
 
 	fmt.Println(personRepo, enemyRepo)
 }
@@ -2,33 +2,183 @@ package mongorepo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// MongoRepo error collection
 var (
-	virtualDelete = bson.M{"$set": bson.M{"deleted": true}}
+	ErrNotFound  = errors.New("Resource is not found")
+	ErrInvalidID = errors.New("Resource ID is not a valid ObjectID")
 )
 
+// Page of resource, with total count of all resource for pagination metadata
+type Page struct {
+	Items []interface{}
+	Total int64
+}
+
 // MongoRepo base class
 type MongoRepo struct {
 	collection  *mongo.Collection
 	constructor func() interface{}
+	timeout     time.Duration // default timeout of each operation, zero means no timeout
+	softDelete  string        // field which marks a virtually deleted resource
+	timestamp   bool          // whether softDelete field is a deletion time instead of a boolean flag
 }
 
-// New creates a new instance of MongoRepo
-func New(coll *mongo.Collection, cons func() interface{}) *MongoRepo {
-	return &MongoRepo{
+// New creates a new instance of MongoRepo, configured by opts
+func New(coll *mongo.Collection, cons func() interface{}, opts ...Option) *MongoRepo {
+	repo := &MongoRepo{
 		collection:  coll,
 		constructor: cons,
+		softDelete:  DefaultSoftDeleteField,
 	}
+
+	for _, opt := range opts {
+		opt(repo)
+	}
+
+	return repo
 }
 
-// Get a list of resource
+// NewWithTimeout creates a new instance of MongoRepo
+// where each operation is bounded by timeout, when the incoming ctx have no deadline
+func NewWithTimeout(coll *mongo.Collection, cons func() interface{}, timeout time.Duration) *MongoRepo {
+	return New(coll, cons, WithTimeout(timeout))
+}
+
+// withTimeout derive a child context bounded by the default timeout
+// ctx is kept as is when it already have a deadline, or when there's no default timeout
+func (r *MongoRepo) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || r.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, r.timeout)
+}
+
+// Get a list of resource, excluding the virtually deleted ones
 func (r *MongoRepo) Get(ctx context.Context) ([]interface{}, error) {
-	cur, err := r.collection.Find(ctx, bson.M{})
+	return r.find(ctx, r.active(bson.M{}))
+}
+
+// GetWithDeleted get a list of resource, including the virtually deleted ones
+func (r *MongoRepo) GetWithDeleted(ctx context.Context) ([]interface{}, error) {
+	return r.find(ctx, bson.M{})
+}
+
+// GetBy get a list of resource matching the filter
+// Caller owns the filter shape, which must match the stored document fields
+// Virtually deleted resource is excluded, unless the filter expresses its own soft delete criteria
+func (r *MongoRepo) GetBy(ctx context.Context, filter bson.M) ([]interface{}, error) {
+	return r.find(ctx, r.active(filter))
+}
+
+// GetSorted get a list of resource ordered by sort, excluding the virtually deleted ones
+// e.g. bson.D{{Key: "name", Value: 1}} for ascending name
+func (r *MongoRepo) GetSorted(ctx context.Context, sort bson.D) ([]interface{}, error) {
+	return r.find(ctx, r.active(bson.M{}), options.Find().SetSort(sort))
+}
+
+// GetPaged get a page of resource, skipping the first skip resource and returning at most limit resource
+func (r *MongoRepo) GetPaged(ctx context.Context, skip, limit int64) (*Page, error) {
+	filter := r.active(bson.M{})
+	items, err := r.find(ctx, filter, options.Find().SetSkip(skip).SetLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := r.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Page{Items: items, Total: total}, nil
+}
+
+// Count resource matching the filter, including the virtually deleted ones
+func (r *MongoRepo) Count(ctx context.Context, filter bson.M) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// CountActive count resource matching the filter, excluding the virtually deleted ones
+func (r *MongoRepo) CountActive(ctx context.Context, filter bson.M) (int64, error) {
+	return r.Count(ctx, r.active(filter))
+}
+
+// objectID parse the hex id, returns wrapped ErrInvalidID when id is malformed
+func objectID(id string) (primitive.ObjectID, error) {
+	_id, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return _id, fmt.Errorf("%w: %s", ErrInvalidID, err)
+	}
+
+	return _id, nil
+}
+
+// active copy the filter, excluding virtually deleted resource
+// unless the filter already have its own soft delete criteria
+func (r *MongoRepo) active(filter bson.M) bson.M {
+	result := bson.M{r.softDelete: bson.M{"$ne": true}}
+	if r.timestamp {
+		result[r.softDelete] = nil // matches both null and missing field
+	}
+
+	for key, value := range filter {
+		result[key] = value
+	}
+
+	return result
+}
+
+// deleted filter of virtually deleted resource, merged with the given filter
+func (r *MongoRepo) deleted(filter bson.M) bson.M {
+	result := bson.M{r.softDelete: true}
+	if r.timestamp {
+		result[r.softDelete] = bson.M{"$ne": nil}
+	}
+
+	for key, value := range filter {
+		result[key] = value
+	}
+
+	return result
+}
+
+// virtualDelete update which marks a resource as virtually deleted
+func (r *MongoRepo) virtualDelete() bson.M {
+	if r.timestamp {
+		return bson.M{"$set": bson.M{r.softDelete: time.Now()}}
+	}
+
+	return bson.M{"$set": bson.M{r.softDelete: true}}
+}
+
+// virtualRestore update which marks a virtually deleted resource as active
+func (r *MongoRepo) virtualRestore() bson.M {
+	if r.timestamp {
+		return bson.M{"$unset": bson.M{r.softDelete: ""}}
+	}
+
+	return bson.M{"$set": bson.M{r.softDelete: false}}
+}
+
+// find resource matching the filter, and decode them using constructor
+func (r *MongoRepo) find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) ([]interface{}, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	cur, err := r.collection.Find(ctx, filter, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -43,20 +193,116 @@ func (r *MongoRepo) Get(ctx context.Context) ([]interface{}, error) {
 		result = append(result, entry)
 	}
 
-	return result, nil
+	return result, cur.Err()
+}
+
+// Stream resource matching the filter one at a time, excluding the virtually deleted ones
+// Items channel is closed when the cursor is exhausted, then errs yields the error occurred if any
+// Stream is not bounded by the default timeout, as it may take longer than a single operation
+// Cancel ctx when abandoning the stream, so the cursor is closed
+func (r *MongoRepo) Stream(ctx context.Context, filter bson.M) (<-chan interface{}, <-chan error) {
+	items := make(chan interface{})
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(items)
+
+		cur, err := r.collection.Find(ctx, r.active(filter))
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		// closed with a fresh context, as ctx might have been cancelled
+		defer cur.Close(context.Background())
+		for cur.Next(ctx) {
+			entry := r.constructor()
+			if err = cur.Decode(entry); err != nil {
+				errs <- err
+				return
+			}
+
+			select {
+			case items <- entry:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+
+		if err = cur.Err(); err != nil {
+			errs <- err
+		}
+	}()
+
+	return items, errs
+}
+
+// Aggregate run the pipeline, e.g. $match and $group for reports
+// Each document is decoded by the caller, since aggregation output rarely matches the model
+func (r *MongoRepo) Aggregate(ctx context.Context, pipeline mongo.Pipeline, decode func(*mongo.Cursor) (interface{}, error)) ([]interface{}, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	cur, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []interface{}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		entry, err := decode(cur)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entry)
+	}
+
+	return result, cur.Err()
 }
 
-// GetOne resource based on its ID
+// GetOne resource based on its ID, excluding the virtually deleted one
 func (r *MongoRepo) GetOne(ctx context.Context, id string) (interface{}, error) {
-	_id, _ := primitive.ObjectIDFromHex(id)
-	res := r.collection.FindOne(ctx, bson.M{"_id": _id})
+	_id, err := objectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	res := r.collection.FindOne(ctx, r.active(bson.M{"_id": _id}))
 	dbo := r.constructor()
-	err := res.Decode(dbo)
+	err = res.Decode(dbo)
 	return dbo, err
 }
 
+// GetOneBy get a single resource matching the filter, e.g. bson.M{"email": email}
+// Virtually deleted resource is excluded, unless the filter expresses its own soft delete criteria
+// Returns ErrNotFound when nothing matches
+func (r *MongoRepo) GetOneBy(ctx context.Context, filter bson.M) (interface{}, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	res := r.collection.FindOne(ctx, r.active(filter))
+	dbo := r.constructor()
+	if err := res.Decode(dbo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return dbo, nil
+}
+
 // Create a new resource
 func (r *MongoRepo) Create(ctx context.Context, obj interface{}) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	_, err := r.collection.InsertOne(ctx, obj)
 	if err != nil {
 		return err
@@ -65,24 +311,96 @@ func (r *MongoRepo) Create(ctx context.Context, obj interface{}) error {
 	return nil
 }
 
-// Update a resource
-func (r *MongoRepo) Update(ctx context.Context, id string, obj interface{}) error {
-	_id, _ := primitive.ObjectIDFromHex(id)
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": _id}, obj)
+// Update a resource with a plain object, just like Create
+// obj is wrapped as {"$set": obj}, so only the marshalled fields are updated
+// Use omitempty bson tags or a bson.M to do partial update
+// Returns the number of matched resource, zero when id does not exist
+func (r *MongoRepo) Update(ctx context.Context, id string, obj interface{}) (matched int64, err error) {
+	return r.UpdateRaw(ctx, id, bson.M{"$set": obj})
+}
+
+// UpdateRaw update a resource with full control of the update operators, e.g. {"$inc": {"count": 1}}
+// Returns the number of matched resource, zero when id does not exist
+func (r *MongoRepo) UpdateRaw(ctx context.Context, id string, update interface{}) (matched int64, err error) {
+	_id, err := objectID(id)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": _id}, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.MatchedCount, nil
+}
+
+// Delete a resource virtually, using the configured soft delete mode
+// e.g: setting the soft delete field to true, or to the deletion time when WithSoftDeleteTimestamp
+// Returns the number of matched resource, zero when id does not exist
+func (r *MongoRepo) Delete(ctx context.Context, id string) (matched int64, err error) {
+	_id, err := objectID(id)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": _id}, r.virtualDelete())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.MatchedCount, nil
+}
+
+// HardDelete a resource, permanently removing it from collection
+// Returns ErrNotFound when nothing is removed
+func (r *MongoRepo) HardDelete(ctx context.Context, id string) error {
+	_id, err := objectID(id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": _id})
 	if err != nil {
 		return err
 	}
 
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
 	return nil
 }
 
-// Delete a resource, virtually by marking it as {"deleted": true}
-func (r *MongoRepo) Delete(ctx context.Context, id string) error {
-	_id, _ := primitive.ObjectIDFromHex(id)
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": _id}, virtualDelete)
+// Restore a virtually deleted resource, using the configured soft delete mode
+// e.g: setting the soft delete field to false, or unsetting the deletion time when WithSoftDeleteTimestamp
+// Returns ErrNotFound when id does not exist, or the resource is not deleted
+func (r *MongoRepo) Restore(ctx context.Context, id string) error {
+	_id, err := objectID(id)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.collection.UpdateOne(ctx, r.deleted(bson.M{"_id": _id}), r.virtualRestore())
 	if err != nil {
 		return err
 	}
 
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
 	return nil
 }
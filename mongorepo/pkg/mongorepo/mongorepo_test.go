@@ -0,0 +1,674 @@
+package mongorepo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bastianrob/go-experiences/mongorepo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const ns = "dbtest.person"
+
+// newPersonRepo creates a person repo on top of mocked collection
+func newPersonRepo(mt *mtest.T) *MongoRepo {
+	return New(mt.Coll, func() interface{} {
+		return &models.Person{}
+	})
+}
+
+// people creates mocked cursor batch of person documents
+func people(names ...string) []bson.D {
+	docs := make([]bson.D, len(names))
+	for i, name := range names {
+		docs[i] = bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "name", Value: name}}
+	}
+
+	return docs
+}
+
+// cursor creates mocked find responses returning all docs in the first batch
+func cursor(docs ...bson.D) []bson.D {
+	return []bson.D{
+		mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, docs...),
+		mtest.CreateCursorResponse(0, ns, mtest.NextBatch),
+	}
+}
+
+// count creates mocked CountDocuments response
+func count(n int64) []bson.D {
+	return cursor(bson.D{{Key: "n", Value: n}})
+}
+
+// names of decoded persons
+func names(items []interface{}) []string {
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = item.(*models.Person).Name
+	}
+
+	return result
+}
+
+func Test_MongoRepo_GetPaged(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("paged", func(mt *mtest.T) {
+		repo := newPersonRepo(mt)
+		mt.AddMockResponses(cursor(people("Jojo", "Dio")...)...)
+		mt.AddMockResponses(count(5)...)
+
+		page, err := repo.GetPaged(context.Background(), 2, 2)
+		if err != nil {
+			mt.Fatal("GetPaged must not return error, got:", err)
+		}
+		if got := names(page.Items); len(got) != 2 || got[0] != "Jojo" || got[1] != "Dio" {
+			mt.Error("GetPaged should decode the page items, got:", got)
+		}
+		if page.Total != 5 {
+			mt.Error("GetPaged should return total count of 5, got:", page.Total)
+		}
+
+		find := mt.GetAllStartedEvents()[0].Command
+		if skip := find.Lookup("skip").AsInt64(); skip != 2 {
+			mt.Error("GetPaged should skip 2, got:", skip)
+		}
+		if limit := find.Lookup("limit").AsInt64(); limit != 2 {
+			mt.Error("GetPaged should limit 2, got:", limit)
+		}
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		repo := newPersonRepo(mt)
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad"}))
+
+		if _, err := repo.GetPaged(context.Background(), 0, 10); err == nil {
+			mt.Error("GetPaged should return find error")
+		}
+	})
+}
+
+func Test_MongoRepo_GetBy(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("filtered", func(mt *mtest.T) {
+		repo := newPersonRepo(mt)
+		mt.AddMockResponses(cursor(people("Jojo")...)...)
+
+		items, err := repo.GetBy(context.Background(), bson.M{"name": "Jojo"})
+		if err != nil {
+			mt.Fatal("GetBy must not return error, got:", err)
+		}
+		if got := names(items); len(got) != 1 || got[0] != "Jojo" {
+			mt.Error("GetBy should decode the matching items, got:", got)
+		}
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		if name := filter.Lookup("name").StringValue(); name != "Jojo" {
+			mt.Error("GetBy should send the filter, got:", filter)
+		}
+	})
+}
+
+func Test_MongoRepo_GetOneBy(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("found", func(mt *mtest.T) {
+		repo := newPersonRepo(mt)
+		mt.AddMockResponses(cursor(people("Jojo")...)...)
+
+		item, err := repo.GetOneBy(context.Background(), bson.M{"name": "Jojo"})
+		if err != nil {
+			mt.Fatal("GetOneBy must not return error, got:", err)
+		}
+		if person := item.(*models.Person); person.Name != "Jojo" {
+			mt.Error("GetOneBy should decode the matching item, got:", person)
+		}
+
+		cmd := mt.GetStartedEvent().Command
+		filter := cmd.Lookup("filter").Document()
+		if name := filter.Lookup("name").StringValue(); name != "Jojo" {
+			mt.Error("GetOneBy should send the filter, got:", filter)
+		}
+		if _, err := filter.LookupErr("deleted"); err != nil {
+			mt.Error("GetOneBy should exclude deleted document, got:", filter)
+		}
+		if limit, ok := cmd.Lookup("limit").AsInt64OK(); !ok || limit != 1 {
+			mt.Error("GetOneBy should only fetch a single document, got:", cmd.Lookup("limit"))
+		}
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor()...)
+
+		item, err := newPersonRepo(mt).GetOneBy(context.Background(), bson.M{"name": "Dio"})
+		if err != ErrNotFound {
+			mt.Error("GetOneBy should return ErrNotFound, got:", err)
+		}
+		if item != nil {
+			mt.Error("GetOneBy should return nil when not found, got:", item)
+		}
+	})
+}
+
+func Test_MongoRepo_SoftDelete(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	// excluded checks whether the sent filter excludes virtually deleted document
+	excluded := func(mt *mtest.T) bool {
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		deleted, err := filter.LookupErr("deleted")
+		if err != nil {
+			return false
+		}
+
+		ne, err := deleted.Document().LookupErr("$ne")
+		return err == nil && ne.Boolean()
+	}
+
+	mt.Run("Get hides deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor(people("Jojo")...)...)
+		newPersonRepo(mt).Get(context.Background())
+		if !excluded(mt) {
+			mt.Error("Get should exclude deleted document")
+		}
+	})
+
+	mt.Run("GetOne hides deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor(people("Jojo")...)...)
+		newPersonRepo(mt).GetOne(context.Background(), primitive.NewObjectID().Hex())
+		if !excluded(mt) {
+			mt.Error("GetOne should exclude deleted document")
+		}
+	})
+
+	mt.Run("GetBy hides deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor(people("Jojo")...)...)
+		newPersonRepo(mt).GetBy(context.Background(), bson.M{"name": "Jojo"})
+		if !excluded(mt) {
+			mt.Error("GetBy should exclude deleted document")
+		}
+	})
+
+	mt.Run("GetBy with deleted criteria", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor(people("Dio")...)...)
+		newPersonRepo(mt).GetBy(context.Background(), bson.M{"deleted": true})
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		if deleted, ok := filter.Lookup("deleted").BooleanOK(); !ok || !deleted {
+			mt.Error("GetBy should keep the caller deleted criteria, got:", filter)
+		}
+	})
+
+	mt.Run("GetWithDeleted shows deleted", func(mt *mtest.T) {
+		deleted := people("Dio")[0]
+		deleted = append(deleted, bson.E{Key: "deleted", Value: true})
+		mt.AddMockResponses(cursor(people("Jojo")[0], deleted)...)
+
+		items, err := newPersonRepo(mt).GetWithDeleted(context.Background())
+		if err != nil {
+			mt.Fatal("GetWithDeleted must not return error, got:", err)
+		}
+		if excluded(mt) {
+			mt.Error("GetWithDeleted should not exclude deleted document")
+		}
+		if got := names(items); len(got) != 2 || got[1] != "Dio" {
+			mt.Error("GetWithDeleted should return deleted document, got:", got)
+		}
+	})
+}
+
+func Test_MongoRepo_GetSorted(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("sorted", func(mt *mtest.T) {
+		repo := newPersonRepo(mt)
+		mt.AddMockResponses(cursor(people("Dio", "Jojo", "Polnareff")...)...)
+
+		items, err := repo.GetSorted(context.Background(), bson.D{{Key: "name", Value: 1}})
+		if err != nil {
+			mt.Fatal("GetSorted must not return error, got:", err)
+		}
+		if got := names(items); len(got) != 3 || got[0] != "Dio" || got[1] != "Jojo" || got[2] != "Polnareff" {
+			mt.Error("GetSorted should keep the requested order, got:", got)
+		}
+
+		sort := mt.GetStartedEvent().Command.Lookup("sort").Document()
+		if name := sort.Lookup("name").Int32(); name != 1 {
+			mt.Error("GetSorted should send the sort, got:", sort)
+		}
+	})
+}
+
+func Test_MongoRepo_Count(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("count", func(mt *mtest.T) {
+		mt.AddMockResponses(count(3)...)
+		n, err := newPersonRepo(mt).Count(context.Background(), bson.M{})
+		if err != nil || n != 3 {
+			mt.Error("Count should return 3, got:", n, err)
+		}
+	})
+
+	mt.Run("empty collection", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor()...)
+		n, err := newPersonRepo(mt).Count(context.Background(), bson.M{})
+		if err != nil || n != 0 {
+			mt.Error("Count of empty collection should return 0, got:", n, err)
+		}
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad"}))
+		if _, err := newPersonRepo(mt).Count(context.Background(), bson.M{}); err == nil {
+			mt.Error("Count should return the error")
+		}
+	})
+
+	mt.Run("active", func(mt *mtest.T) {
+		mt.AddMockResponses(count(2)...)
+		n, err := newPersonRepo(mt).CountActive(context.Background(), bson.M{"name": "Jojo"})
+		if err != nil || n != 2 {
+			mt.Error("CountActive should return 2, got:", n, err)
+		}
+
+		// CountDocuments is an aggregate with $match stage
+		pipeline := mt.GetStartedEvent().Command.Lookup("pipeline").Array()
+		match := pipeline.Index(0).Value().Document().Lookup("$match").Document()
+		if _, err := match.LookupErr("deleted"); err != nil {
+			mt.Error("CountActive should exclude deleted document, got:", match)
+		}
+	})
+}
+
+func Test_MongoRepo_HardDelete(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+
+	mt.Run("deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		if err := newPersonRepo(mt).HardDelete(context.Background(), id.Hex()); err != nil {
+			mt.Error("HardDelete should not return error, got:", err)
+		}
+
+		started := mt.GetStartedEvent()
+		if started.CommandName != "delete" {
+			mt.Error("HardDelete should send delete command, got:", started.CommandName)
+		}
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+		if err := newPersonRepo(mt).HardDelete(context.Background(), id.Hex()); err != ErrNotFound {
+			mt.Error("HardDelete should return ErrNotFound, got:", err)
+		}
+	})
+}
+
+func Test_MongoRepo_Restore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	jojo := people("Jojo")[0]
+	id := jojo.Map()["_id"].(primitive.ObjectID).Hex()
+
+	mt.Run("delete then restore", func(mt *mtest.T) {
+		repo := newPersonRepo(mt)
+		updated := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})
+		mt.AddMockResponses(updated)
+		mt.AddMockResponses(cursor()...)
+		mt.AddMockResponses(updated)
+		mt.AddMockResponses(cursor(jojo)...)
+
+		if matched, err := repo.Delete(context.Background(), id); err != nil || matched != 1 {
+			mt.Fatal("Delete should match 1 resource without error, got:", matched, err)
+		}
+		if items, _ := repo.Get(context.Background()); len(items) != 0 {
+			mt.Error("Deleted document should be hidden from Get, got:", names(items))
+		}
+
+		if err := repo.Restore(context.Background(), id); err != nil {
+			mt.Fatal("Restore should not return error, got:", err)
+		}
+		// the last update command is the restore
+		var restore bson.Raw
+		for _, started := range mt.GetAllStartedEvents() {
+			if started.CommandName == "update" {
+				restore = started.Command.Lookup("updates").Array().Index(0).Value().Document()
+			}
+		}
+		if deleted := restore.Lookup("u", "$set", "deleted").Boolean(); deleted {
+			mt.Error("Restore should mark document as not deleted, got:", restore)
+		}
+
+		if items, _ := repo.Get(context.Background()); len(items) != 1 || names(items)[0] != "Jojo" {
+			mt.Error("Restored document should reappear in Get, got:", names(items))
+		}
+	})
+
+	mt.Run("nothing to restore", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		if err := newPersonRepo(mt).Restore(context.Background(), id); err != ErrNotFound {
+			mt.Error("Restore should return ErrNotFound, got:", err)
+		}
+	})
+
+	mt.Run("document is not deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		if err := newPersonRepo(mt).Restore(context.Background(), id); err != ErrNotFound {
+			mt.Error("Restoring an active document should return ErrNotFound, got:", err)
+		}
+
+		filter := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		if deleted, ok := filter.Lookup("deleted").BooleanOK(); !ok || !deleted {
+			mt.Error("Restore should only match deleted document, got:", filter)
+		}
+	})
+}
+
+func Test_MongoRepo_InvalidID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("invalid id", func(mt *mtest.T) {
+		repo := newPersonRepo(mt)
+		ctx := context.Background()
+		invalid := "not-an-object-id"
+
+		if _, err := repo.GetOne(ctx, invalid); !errors.Is(err, ErrInvalidID) {
+			mt.Error("GetOne should return ErrInvalidID, got:", err)
+		}
+		if _, err := repo.Update(ctx, invalid, bson.M{}); !errors.Is(err, ErrInvalidID) {
+			mt.Error("Update should return ErrInvalidID, got:", err)
+		}
+		if _, err := repo.Delete(ctx, invalid); !errors.Is(err, ErrInvalidID) {
+			mt.Error("Delete should return ErrInvalidID, got:", err)
+		}
+		if err := repo.HardDelete(ctx, invalid); !errors.Is(err, ErrInvalidID) {
+			mt.Error("HardDelete should return ErrInvalidID, got:", err)
+		}
+		if err := repo.Restore(ctx, invalid); !errors.Is(err, ErrInvalidID) {
+			mt.Error("Restore should return ErrInvalidID, got:", err)
+		}
+
+		if started := mt.GetAllStartedEvents(); len(started) != 0 {
+			mt.Error("Invalid id must not be queried, got:", len(started))
+		}
+	})
+}
+
+func Test_MongoRepo_Update(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID().Hex()
+	updated := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})
+
+	// sentUpdate get the update document of the update command
+	sentUpdate := func(mt *mtest.T) bson.Raw {
+		return mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+	}
+
+	mt.Run("plain struct", func(mt *mtest.T) {
+		mt.AddMockResponses(updated)
+		matched, err := newPersonRepo(mt).Update(context.Background(), id, &models.Person{Name: "Dio"})
+		if err != nil {
+			mt.Fatal("Update should not return error, got:", err)
+		}
+		if matched != 1 {
+			mt.Error("Update should report 1 matched resource, got:", matched)
+		}
+
+		update := sentUpdate(mt)
+		if name := update.Lookup("$set", "name").StringValue(); name != "Dio" {
+			mt.Error("Update should wrap the struct in $set, got:", update)
+		}
+		if _, err := update.LookupErr("$set", "_id"); err == nil {
+			mt.Error("Update should not set an empty _id, got:", update)
+		}
+	})
+
+	mt.Run("partial", func(mt *mtest.T) {
+		mt.AddMockResponses(updated)
+		if _, err := newPersonRepo(mt).Update(context.Background(), id, bson.M{"name": "Dio"}); err != nil {
+			mt.Fatal("Update should not return error, got:", err)
+		}
+
+		set := sentUpdate(mt).Lookup("$set").Document()
+		if elems, _ := set.Elements(); len(elems) != 1 {
+			mt.Error("Partial update should only set the given fields, got:", set)
+		}
+	})
+
+	mt.Run("raw", func(mt *mtest.T) {
+		mt.AddMockResponses(updated)
+		if _, err := newPersonRepo(mt).UpdateRaw(context.Background(), id, bson.M{"$inc": bson.M{"visits": 1}}); err != nil {
+			mt.Fatal("UpdateRaw should not return error, got:", err)
+		}
+
+		update := sentUpdate(mt)
+		if _, err := update.LookupErr("$inc", "visits"); err != nil {
+			mt.Error("UpdateRaw should send the update as is, got:", update)
+		}
+	})
+
+	mt.Run("unmatched", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		matched, err := newPersonRepo(mt).Update(context.Background(), id, bson.M{"name": "Dio"})
+		if err != nil {
+			mt.Fatal("Update should not return error, got:", err)
+		}
+		if matched != 0 {
+			mt.Error("Update of unknown id should report 0 matched resource, got:", matched)
+		}
+	})
+}
+
+func Test_MongoRepo_Timeout(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	cons := func() interface{} { return &models.Person{} }
+
+	mt.Run("default deadline", func(mt *mtest.T) {
+		repo := NewWithTimeout(mt.Coll, cons, 1*time.Second)
+		ctx, cancel := repo.withTimeout(context.Background())
+		defer cancel()
+
+		if _, ok := ctx.Deadline(); !ok {
+			mt.Error("Context without deadline should be bounded by the default timeout")
+		}
+	})
+
+	mt.Run("keeps caller deadline", func(mt *mtest.T) {
+		repo := NewWithTimeout(mt.Coll, cons, 1*time.Second)
+		parent, cancelParent := context.WithTimeout(context.Background(), 1*time.Hour)
+		defer cancelParent()
+
+		ctx, cancel := repo.withTimeout(parent)
+		defer cancel()
+		if ctx != parent {
+			mt.Error("Context with deadline should be kept as is")
+		}
+	})
+
+	mt.Run("cancelled context", func(mt *mtest.T) {
+		repo := NewWithTimeout(mt.Coll, cons, 1*time.Second)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		start := time.Now()
+		if _, err := repo.Get(ctx); !errors.Is(err, context.Canceled) {
+			mt.Error("Get with cancelled context should return context.Canceled, got:", err)
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			mt.Error("Get with cancelled context should return promptly, took:", elapsed)
+		}
+	})
+}
+
+func Test_MongoRepo_Aggregate(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"name": "Jojo"}}},
+		{{Key: "$count", Value: "total"}},
+	}
+
+	// decodeTotal decodes the $count output
+	decodeTotal := func(cur *mongo.Cursor) (interface{}, error) {
+		var out struct {
+			Total int64 `bson:"total"`
+		}
+		err := cur.Decode(&out)
+		return out.Total, err
+	}
+
+	mt.Run("count", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor(bson.D{{Key: "total", Value: int64(4)}})...)
+
+		result, err := newPersonRepo(mt).Aggregate(context.Background(), pipeline, decodeTotal)
+		if err != nil {
+			mt.Fatal("Aggregate must not return error, got:", err)
+		}
+		if len(result) != 1 || result[0] != int64(4) {
+			mt.Error("Aggregate should return the decoded count, got:", result)
+		}
+
+		started := mt.GetStartedEvent()
+		if stages, _ := started.Command.Lookup("pipeline").Array().Values(); len(stages) != 2 {
+			mt.Error("Aggregate should send the pipeline, got:", started.Command)
+		}
+	})
+
+	mt.Run("decode error", func(mt *mtest.T) {
+		mt.AddMockResponses(cursor(bson.D{{Key: "total", Value: "four"}})...)
+
+		if _, err := newPersonRepo(mt).Aggregate(context.Background(), pipeline, decodeTotal); err == nil {
+			mt.Error("Aggregate should return the decode error")
+		}
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 2, Message: "bad"}))
+
+		if _, err := newPersonRepo(mt).Aggregate(context.Background(), pipeline, decodeTotal); err == nil {
+			mt.Error("Aggregate should return the aggregate error")
+		}
+	})
+}
+
+func Test_MongoRepo_Stream(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("consumed", func(mt *mtest.T) {
+		repo := newPersonRepo(mt)
+		mt.AddMockResponses(cursor(people("Jojo", "Dio", "Jotaro")...)...)
+
+		items, errs := repo.Stream(context.Background(), bson.M{"name": bson.M{"$ne": "Pucci"}})
+		var streamed []interface{}
+		for item := range items {
+			streamed = append(streamed, item)
+		}
+		if err := <-errs; err != nil {
+			mt.Fatal("Stream must not return error, got:", err)
+		}
+		if got := names(streamed); len(got) != 3 || got[0] != "Jojo" || got[2] != "Jotaro" {
+			mt.Error("Stream should decode all items in order, got:", got)
+		}
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		if _, err := filter.LookupErr("deleted"); err != nil {
+			mt.Error("Stream should exclude virtually deleted resource, got:", filter)
+		}
+	})
+
+	mt.Run("abandoned", func(mt *mtest.T) {
+		repo := newPersonRepo(mt)
+		mt.AddMockResponses(cursor(people("Jojo", "Dio", "Jotaro")...)...)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		items, errs := repo.Stream(ctx, bson.M{})
+		<-items // consumer only takes the first item, then abandons the stream
+		cancel()
+
+		select {
+		case err := <-errs:
+			if !errors.Is(err, context.Canceled) {
+				mt.Error("Abandoned stream should return context.Canceled, got:", err)
+			}
+		case <-time.After(1 * time.Second):
+			mt.Fatal("Abandoned stream should stop")
+		}
+
+		for range items {
+			// items is closed after the cursor is closed
+		}
+
+		var killed bool
+		for _, evt := range mt.GetAllStartedEvents() {
+			killed = killed || evt.CommandName == "killCursors"
+		}
+		if !killed {
+			mt.Error("Abandoned stream should close its cursor")
+		}
+	})
+}
+
+func Test_MongoRepo_SoftDeleteOptions(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	cons := func() interface{} { return &models.Person{} }
+	id := primitive.NewObjectID().Hex()
+	updated := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1})
+
+	// sentUpdate get the update document of the update command
+	sentUpdate := func(mt *mtest.T) bson.Raw {
+		return mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u").Document()
+	}
+
+	mt.Run("field name", func(mt *mtest.T) {
+		repo := New(mt.Coll, cons, WithSoftDeleteField("is_deleted"))
+		mt.AddMockResponses(updated)
+		mt.AddMockResponses(cursor(people("Jojo")...)...)
+
+		repo.Delete(context.Background(), id)
+		if deleted, ok := sentUpdate(mt).Lookup("$set", "is_deleted").BooleanOK(); !ok || !deleted {
+			mt.Error("Delete should mark is_deleted as true")
+		}
+
+		repo.Get(context.Background())
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		if _, err := filter.LookupErr("is_deleted", "$ne"); err != nil {
+			mt.Error("Get should exclude is_deleted document, got:", filter)
+		}
+		if _, err := filter.LookupErr("deleted"); err == nil {
+			mt.Error("Get should not filter by the default deleted field, got:", filter)
+		}
+	})
+
+	mt.Run("timestamp", func(mt *mtest.T) {
+		repo := New(mt.Coll, cons, WithSoftDeleteTimestamp("deletedAt"))
+		mt.AddMockResponses(updated)
+		mt.AddMockResponses(updated)
+		mt.AddMockResponses(cursor(people("Jojo")...)...)
+
+		before := time.Now().Add(-1 * time.Second)
+		repo.Delete(context.Background(), id)
+		deletedAt, ok := sentUpdate(mt).Lookup("$set", "deletedAt").DateTimeOK()
+		if !ok || time.Unix(0, deletedAt*int64(time.Millisecond)).Before(before) {
+			mt.Error("Delete should set deletedAt to the deletion time")
+		}
+
+		repo.Restore(context.Background(), id)
+		restore := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if _, err := restore.LookupErr("u", "$unset", "deletedAt"); err != nil {
+			mt.Error("Restore should unset deletedAt")
+		}
+		if value, err := restore.LookupErr("q", "deletedAt", "$ne"); err != nil || value.Type != bson.TypeNull {
+			mt.Error("Restore should only match document with deletedAt, got:", restore)
+		}
+
+		repo.Get(context.Background())
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		if value := filter.Lookup("deletedAt"); value.Type != bson.TypeNull {
+			mt.Error("Get should only return document without deletedAt, got:", filter)
+		}
+	})
+}
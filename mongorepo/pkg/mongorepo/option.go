@@ -0,0 +1,36 @@
+package mongorepo
+
+import (
+	"time"
+)
+
+// DefaultSoftDeleteField is the field which marks a resource as virtually deleted
+const DefaultSoftDeleteField = "deleted"
+
+// Option configures a MongoRepo on New
+type Option func(*MongoRepo)
+
+// WithTimeout bounds each operation by timeout, when the incoming ctx have no deadline
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *MongoRepo) {
+		r.timeout = timeout
+	}
+}
+
+// WithSoftDeleteField marks virtually deleted resource with a boolean field other than "deleted"
+// e.g: "is_deleted"
+func WithSoftDeleteField(field string) Option {
+	return func(r *MongoRepo) {
+		r.softDelete = field
+		r.timestamp = false
+	}
+}
+
+// WithSoftDeleteTimestamp marks virtually deleted resource with the time it is deleted, e.g: "deletedAt"
+// Active resource have no such field, and restoring a resource unsets it
+func WithSoftDeleteTimestamp(field string) Option {
+	return func(r *MongoRepo) {
+		r.softDelete = field
+		r.timestamp = true
+	}
+}
@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/bastianrob/go-experiences/mongorepo/pkg/mongorepo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Repo is a type safe MongoRepo of T
+// Behaves the same as MongoRepo, including virtual delete, without the interface{} juggling
+type Repo[T any] struct {
+	base *mongorepo.MongoRepo
+}
+
+// Page of T, with total count of all resource for pagination metadata
+type Page[T any] struct {
+	Items []T
+	Total int64
+}
+
+// New creates a new instance of Repo of T, configured by the same options as MongoRepo
+func New[T any](coll *mongo.Collection, opts ...mongorepo.Option) *Repo[T] {
+	return &Repo[T]{
+		base: mongorepo.New(coll, func() interface{} {
+			return new(T)
+		}, opts...),
+	}
+}
+
+// Get a list of resource, excluding the virtually deleted ones
+func (r *Repo[T]) Get(ctx context.Context) ([]T, error) {
+	return collect[T](r.base.Get(ctx))
+}
+
+// GetWithDeleted get a list of resource, including the virtually deleted ones
+func (r *Repo[T]) GetWithDeleted(ctx context.Context) ([]T, error) {
+	return collect[T](r.base.GetWithDeleted(ctx))
+}
+
+// GetBy get a list of resource matching the filter, excluding the virtually deleted ones
+func (r *Repo[T]) GetBy(ctx context.Context, filter bson.M) ([]T, error) {
+	return collect[T](r.base.GetBy(ctx, filter))
+}
+
+// GetSorted get a list of resource ordered by sort, excluding the virtually deleted ones
+func (r *Repo[T]) GetSorted(ctx context.Context, sort bson.D) ([]T, error) {
+	return collect[T](r.base.GetSorted(ctx, sort))
+}
+
+// GetPaged get a page of resource, skipping the first skip resource and returning at most limit resource
+func (r *Repo[T]) GetPaged(ctx context.Context, skip, limit int64) (*Page[T], error) {
+	page, err := r.base.GetPaged(ctx, skip, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items, _ := collect[T](page.Items, nil)
+	return &Page[T]{Items: items, Total: page.Total}, nil
+}
+
+// Count resource matching the filter, including the virtually deleted ones
+func (r *Repo[T]) Count(ctx context.Context, filter bson.M) (int64, error) {
+	return r.base.Count(ctx, filter)
+}
+
+// CountActive count resource matching the filter, excluding the virtually deleted ones
+func (r *Repo[T]) CountActive(ctx context.Context, filter bson.M) (int64, error) {
+	return r.base.CountActive(ctx, filter)
+}
+
+// Stream resource matching the filter one at a time, excluding the virtually deleted ones
+// Behaves the same as MongoRepo.Stream, cancel ctx when abandoning the stream
+func (r *Repo[T]) Stream(ctx context.Context, filter bson.M) (<-chan T, <-chan error) {
+	entries, errs := r.base.Stream(ctx, filter)
+	items := make(chan T)
+
+	go func() {
+		defer close(items)
+		for entry := range entries {
+			select {
+			case items <- *entry.(*T):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return items, errs
+}
+
+// GetOne resource based on its ID, excluding the virtually deleted one
+func (r *Repo[T]) GetOne(ctx context.Context, id string) (T, error) {
+	var zero T
+	dbo, err := r.base.GetOne(ctx, id)
+	if err != nil {
+		return zero, err
+	}
+
+	return *dbo.(*T), nil
+}
+
+// GetOneBy get a single resource matching the filter, excluding the virtually deleted one
+// Returns mongorepo.ErrNotFound when nothing matches
+func (r *Repo[T]) GetOneBy(ctx context.Context, filter bson.M) (T, error) {
+	var zero T
+	dbo, err := r.base.GetOneBy(ctx, filter)
+	if err != nil {
+		return zero, err
+	}
+
+	return *dbo.(*T), nil
+}
+
+// Create a new resource
+func (r *Repo[T]) Create(ctx context.Context, obj T) error {
+	return r.base.Create(ctx, obj)
+}
+
+// Update a resource, only the marshalled fields of obj are updated
+// Returns the number of matched resource, zero when id does not exist
+func (r *Repo[T]) Update(ctx context.Context, id string, obj T) (int64, error) {
+	return r.base.Update(ctx, id, obj)
+}
+
+// UpdateRaw update a resource with full control of the update operators, e.g. {"$inc": {"count": 1}}
+// Returns the number of matched resource, zero when id does not exist
+func (r *Repo[T]) UpdateRaw(ctx context.Context, id string, update interface{}) (int64, error) {
+	return r.base.UpdateRaw(ctx, id, update)
+}
+
+// Delete a resource virtually, using the soft delete mode of the underlying MongoRepo
+// e.g: a boolean field, or the deletion time when WithSoftDeleteTimestamp
+// Returns the number of matched resource, zero when id does not exist
+func (r *Repo[T]) Delete(ctx context.Context, id string) (int64, error) {
+	return r.base.Delete(ctx, id)
+}
+
+// Restore a virtually deleted resource
+func (r *Repo[T]) Restore(ctx context.Context, id string) error {
+	return r.base.Restore(ctx, id)
+}
+
+// HardDelete a resource, permanently removing it from collection
+func (r *Repo[T]) HardDelete(ctx context.Context, id string) error {
+	return r.base.HardDelete(ctx, id)
+}
+
+// Aggregate run the pipeline on repo's collection, decoding each document into R
+// R is usually a report type, since aggregation output rarely matches T
+func Aggregate[R, T any](ctx context.Context, r *Repo[T], pipeline mongo.Pipeline) ([]R, error) {
+	return collect[R](r.base.Aggregate(ctx, pipeline, func(cur *mongo.Cursor) (interface{}, error) {
+		entry := new(R)
+		return entry, cur.Decode(entry)
+	}))
+}
+
+// collect the decoded *T entries into []T
+func collect[T any](entries []interface{}, err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, len(entries))
+	for i, entry := range entries {
+		result[i] = *entry.(*T)
+	}
+
+	return result, nil
+}
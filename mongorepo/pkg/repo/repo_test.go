@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bastianrob/go-experiences/mongorepo/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const ns = "dbtest.person"
+
+func Test_Repo(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	jojo := models.Person{ID: primitive.NewObjectID(), Name: "Jojo"}
+	dio := models.Person{ID: primitive.NewObjectID(), Name: "Dio"}
+	doc := func(p models.Person) bson.D {
+		return bson.D{{Key: "_id", Value: p.ID}, {Key: "name", Value: p.Name}}
+	}
+
+	mt.Run("Get", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, doc(jojo), doc(dio)),
+		)
+
+		people, err := New[models.Person](mt.Coll).Get(context.Background())
+		if err != nil {
+			mt.Fatal("Get must not return error, got:", err)
+		}
+		if len(people) != 2 || people[0] != jojo || people[1] != dio {
+			mt.Error("Get should return typed people, got:", people)
+		}
+
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		if _, err := filter.LookupErr("deleted"); err != nil {
+			mt.Error("Get should exclude deleted document, got:", filter)
+		}
+	})
+
+	mt.Run("GetOne", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, doc(dio)))
+
+		person, err := New[models.Person](mt.Coll).GetOne(context.Background(), dio.ID.Hex())
+		if err != nil {
+			mt.Fatal("GetOne must not return error, got:", err)
+		}
+		if person != dio {
+			mt.Error("GetOne should return typed person, got:", person)
+		}
+	})
+
+	mt.Run("GetOne error", func(mt *mtest.T) {
+		person, err := New[models.Person](mt.Coll).GetOne(context.Background(), "invalid")
+		if err == nil {
+			mt.Error("GetOne with invalid id should return error")
+		}
+		if person != (models.Person{}) {
+			mt.Error("GetOne should return zero value on error, got:", person)
+		}
+	})
+
+	mt.Run("GetOneBy", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, doc(dio)))
+
+		person, err := New[models.Person](mt.Coll).GetOneBy(context.Background(), bson.M{"name": "Dio"})
+		if err != nil {
+			mt.Fatal("GetOneBy must not return error, got:", err)
+		}
+		if person != dio {
+			mt.Error("GetOneBy should return typed person, got:", person)
+		}
+	})
+
+	mt.Run("GetPaged", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, doc(dio)),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: 2}}),
+		)
+
+		page, err := New[models.Person](mt.Coll).GetPaged(context.Background(), 1, 1)
+		if err != nil {
+			mt.Fatal("GetPaged must not return error, got:", err)
+		}
+		if len(page.Items) != 1 || page.Items[0] != dio || page.Total != 2 {
+			mt.Error("GetPaged should return a typed page, got:", page)
+		}
+	})
+
+	mt.Run("Stream", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, doc(jojo), doc(dio)))
+
+		items, errs := New[models.Person](mt.Coll).Stream(context.Background(), bson.M{})
+		var people []models.Person
+		for person := range items {
+			people = append(people, person)
+		}
+		if err := <-errs; err != nil {
+			mt.Fatal("Stream must not return error, got:", err)
+		}
+		if len(people) != 2 || people[0] != jojo || people[1] != dio {
+			mt.Error("Stream should yield typed people, got:", people)
+		}
+	})
+
+	mt.Run("Aggregate", func(mt *mtest.T) {
+		type report struct {
+			Name  string `bson:"_id"`
+			Total int    `bson:"total"`
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "Dio"}, {Key: "total", Value: 2}},
+		))
+
+		reports, err := Aggregate[report](context.Background(), New[models.Person](mt.Coll), nil)
+		if err != nil {
+			mt.Fatal("Aggregate must not return error, got:", err)
+		}
+		if len(reports) != 1 || reports[0] != (report{Name: "Dio", Total: 2}) {
+			mt.Error("Aggregate should decode into the report type, got:", reports)
+		}
+	})
+}
@@ -0,0 +1,13 @@
+package rbac
+
+// AuditEvent is a record of an authorization decision
+type AuditEvent struct {
+	Role     string
+	Resource string
+	Endpoint string
+	Allowed  bool
+	Err      error // reason why request is not allowed, nil if allowed
+}
+
+// AuditFunc receives every authorization decision, e.g: to ship it into a SIEM
+type AuditFunc func(decision AuditEvent)
@@ -0,0 +1,73 @@
+package rbac_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bastianrob/go-experiences/rbac"
+)
+
+func TestCompiledRBAC_Audit(t *testing.T) {
+	compiled := rbac.FromFile("./test.yaml").Compile()
+
+	var decisions []rbac.AuditEvent
+	compiled.Audit = func(decision rbac.AuditEvent) {
+		decisions = append(decisions, decision)
+	}
+
+	tests := []struct {
+		given, then string
+		url         string
+		role        string
+		endpoint    string
+		wantAllowed bool
+		wantErr     error
+	}{{
+		given: "Role is unknown", then: "decision is audited as ErrRoleUnknown",
+		url:  "http://api.example.com/inquiries",
+		role: "stranger", endpoint: "get",
+		wantErr: rbac.ErrRoleUnknown,
+	}, {
+		given: "Role is Client trying to assign", then: "decision is audited as ErrForbidden",
+		url:  "http://api.example.com/inquiries/INQ-0001/assign",
+		role: "client", endpoint: "assign",
+		wantErr: rbac.ErrForbidden,
+	}, {
+		given: "Role is Client with ?created_by=client.other@email.com", then: "decision is audited with query rule violation",
+		url:  "http://api.example.com/inquiries?created_by=client.other@email.com",
+		role: "client", endpoint: "get",
+	}, {
+		given: "Role is Client with ?created_by=client.one@email.com", then: "decision is audited as allowed",
+		url:  "http://api.example.com/inquiries?created_by=client.one@email.com",
+		role: "client", endpoint: "get",
+		wantAllowed: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			decisions = nil
+			req, _ := http.NewRequest("", tt.url, nil)
+			req = req.WithContext(context.WithValue(context.Background(), rbac.ContextKey("email"), "client.one@email.com"))
+
+			err := compiled.Authorize(req, tt.role, "inquiry", tt.endpoint)
+			if assert.Len(t, decisions, 1, tt.then) {
+				decision := decisions[0]
+				assert.Equal(t, tt.role, decision.Role, tt.then)
+				assert.Equal(t, "inquiry", decision.Resource, tt.then)
+				assert.Equal(t, tt.endpoint, decision.Endpoint, tt.then)
+				assert.Equal(t, tt.wantAllowed, decision.Allowed, tt.then)
+				assert.Equal(t, err, decision.Err, tt.then)
+				if tt.wantAllowed {
+					assert.NoError(t, decision.Err, tt.then)
+				} else {
+					assert.Error(t, decision.Err, tt.then)
+				}
+				if tt.wantErr != nil {
+					assert.Equal(t, tt.wantErr, decision.Err, tt.then)
+				}
+			}
+		})
+	}
+}
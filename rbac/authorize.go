@@ -1,22 +1,72 @@
 package rbac
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 )
 
 // Authorize a request based on its role, resource, and endpoint
+// Resource and endpoint without an exact entry fall back to the Wildcard entry, if any
+// Returns ErrRoleUnknown, ErrResourceUnknown, or ErrEndpointUnknown depending on which level is missing
 func (rbac RBAC) Authorize(r *http.Request, role, resource, endpoint string) error {
-	permission, exists := rbac[role][resource][endpoint]
+	resources, exists := rbac[role]
 	if !exists {
 		return ErrRoleUnknown
 	}
 
+	permission, err := resources.lookup(resource, endpoint)
+	if err != nil {
+		return err
+	}
+
+	return permission.authorize(r)
+}
+
+// authorize a request against a single permission
+func (permission Permission) authorize(r *http.Request) error {
 	if !permission.Allow {
 		return ErrForbidden
 	}
 
+	// Outside of active hours, permission is as good as not allowed
+	if permission.ActiveHours != nil {
+		active, err := permission.ActiveHours.Contains(now())
+		if err != nil {
+			return err
+		}
+		if !active {
+			return ErrOutsideActiveHours
+		}
+	}
+
+	// Deny overrides allow, and is evaluated before anything else
+	err := deny(permission.Deny, r)
+	if err != nil {
+		return err
+	}
+
 	// Ensure query compliance
-	err := permission.Ensure.QueryComplies(r)
+	err = permission.Ensure.QueryComplies(r)
+	if err != nil {
+		return err
+	}
+
+	// Ensure header compliance
+	err = permission.Ensure.HeaderComplies(r)
+	if err != nil {
+		return err
+	}
+
+	// Ensure path compliance
+	err = permission.Ensure.PathComplies(r)
+	if err != nil {
+		return err
+	}
+
+	// Ensure body compliance
+	err = permission.Ensure.BodyComplies(r)
 	if err != nil {
 		return err
 	}
@@ -27,5 +77,90 @@ func (rbac RBAC) Authorize(r *http.Request, role, resource, endpoint string) err
 		return err
 	}
 
+	// Enforce header compliance
+	err = permission.Enforce.HeaderComplies(r)
+	if err != nil {
+		return err
+	}
+
+	// Enforce path compliance
+	err = permission.Enforce.PathComplies(r)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// deny a request when its query matches any of the deny rules
+func deny(rules []Rule, r *http.Request) error {
+	ctx := r.Context()
+	for _, rule := range rules {
+		actual := r.URL.Query().Get(rule.Key)
+		expected, err := rule.FromContextSafe(ctx)
+		if err != nil {
+			return err
+		}
+
+		if rule.Comply(expected, actual) {
+			return fmt.Errorf("Deny rule violation: query '%s' %s '%v' is denied",
+				rule.Key, rule.Operator, expected)
+		}
+	}
+
+	return nil
+}
+
+// AuthorizeAny authorize a request which holds multiple roles
+// Roles are evaluated in the given order, and the first role which authorize the request takes precedence:
+// only its enforce rules are applied to the request, while enforce rules from the other roles are ignored.
+// Each role is evaluated against a copy of the request, so a role which fails to authorize never re-writes it.
+// The body is read once, and each copy gets its own reader, so a role's body rules never consume it for the others.
+// When no role authorize the request, the error from the first role is returned
+func (rbac RBAC) AuthorizeAny(r *http.Request, roles []string, resource, endpoint string) error {
+	if len(roles) <= 0 {
+		return ErrNoRole
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	var first error
+	for _, role := range roles {
+		clone := r.Clone(r.Context())
+		if r.Body != nil {
+			clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		err := rbac.Authorize(clone, role, resource, endpoint)
+		if err == nil {
+			*r = *clone
+			return nil
+		}
+
+		if first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// AuthorizeRequest authorize a request using role stored in its context under ContextKeyRole
+// Returns ErrNoRole if the request context have no role
+func (rbac RBAC) AuthorizeRequest(r *http.Request, resource, endpoint string) error {
+	role, ok := RoleFromContext(r.Context())
+	if !ok {
+		return ErrNoRole
+	}
+
+	return rbac.Authorize(r, role, resource, endpoint)
+}
@@ -3,7 +3,9 @@ package rbac_test
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,9 +14,6 @@ import (
 )
 
 func TestRBAC_Authorize(t *testing.T) {
-	rbo := rbac.FromFile("./test.yaml")
-	fmt.Printf("%+v", rbo)
-
 	type args struct {
 		req      func() *http.Request
 		role     string
@@ -57,6 +56,35 @@ func TestRBAC_Authorize(t *testing.T) {
 				endpoint: "get",
 			},
 			wantErr: true,
+		}, {
+			given: "Role is Client & email = client.one@email.com",
+			when:  "?created_by=client.one@email.com&export=true", then: "is denied",
+			args: args{
+				req: func() *http.Request {
+					req, _ := http.NewRequest("", "http://api.example.com/inquiries?created_by=client.one@email.com&export=true", nil)
+					ctx := context.WithValue(context.Background(), rbac.ContextKey("email"), "client.one@email.com")
+
+					return req.WithContext(ctx)
+				},
+				role:     "client",
+				resource: "inquiry",
+				endpoint: "get",
+			},
+			wantErr: true,
+		}, {
+			given: "Role is Client & email = client.one@email.com",
+			when:  "?created_by=client.one@email.com&export=false", then: "is allowed",
+			args: args{
+				req: func() *http.Request {
+					req, _ := http.NewRequest("", "http://api.example.com/inquiries?created_by=client.one@email.com&export=false", nil)
+					ctx := context.WithValue(context.Background(), rbac.ContextKey("email"), "client.one@email.com")
+
+					return req.WithContext(ctx)
+				},
+				role:     "client",
+				resource: "inquiry",
+				endpoint: "get",
+			},
 		}, {
 			given: "Role is Client & email = client.one@email.com",
 			when:  "query is not given", then: "is not allowed",
@@ -312,18 +340,299 @@ func TestRBAC_Authorize(t *testing.T) {
 			},
 		},
 	}
+	// the same rules are written in both yaml and json, and must produce the same results
+	for _, path := range []string{"./test.yaml", "./test.json"} {
+		rbo, err := rbac.Load(path)
+		if err != nil {
+			t.Fatal("Failed to load", path, err)
+		}
+		fmt.Printf("%+v", rbo)
+
+		for _, tt := range tests {
+			t.Run(path+": "+tt.given, func(t *testing.T) {
+				req := tt.args.req()
+				got := rbo.Authorize(req, tt.args.role, tt.args.resource, tt.args.endpoint)
+				if tt.wantErr {
+					assert.Error(t, got, "when: %s, then: %s", tt.when, tt.then)
+				} else {
+					assert.NoError(t, got, "when: %s, then: %s", tt.when, tt.then)
+					for key, val := range tt.queryResult {
+						assert.Equal(t, val, req.URL.Query().Get(key), "when: %s, then: %s", tt.when, tt.then)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRBAC_AuthorizeAny(t *testing.T) {
+	rbo := rbac.FromFile("./test.yaml")
+	newRequest := func(url string) *http.Request {
+		req, _ := http.NewRequest("", url, nil)
+		ctx := context.WithValue(context.Background(), rbac.ContextKey("email"), "someone@company.com")
+		return req.WithContext(ctx)
+	}
+
+	tests := []struct {
+		given, when, then string
+		req               *http.Request
+		roles             []string
+		wantErr           bool
+		queryResult       map[string]string
+	}{{
+		given: "Roles are CS and Manager",
+		when:  "query is not given", then: "status=New from CS takes precedence",
+		req:   newRequest("http://api.example.com/inquiries"),
+		roles: []string{"cs", "manager"},
+		queryResult: map[string]string{
+			"status": "New",
+		},
+	}, {
+		given: "Roles are Manager and CS",
+		when:  "query is not given", then: "status=Assigned from Manager takes precedence",
+		req:   newRequest("http://api.example.com/inquiries"),
+		roles: []string{"manager", "cs"},
+		queryResult: map[string]string{
+			"status": "Assigned",
+		},
+	}, {
+		given: "Roles are Client and CS",
+		when:  "?created_by is not given", then: "Client is not allowed, but CS is allowed with status=New",
+		req:   newRequest("http://api.example.com/inquiries"),
+		roles: []string{"client", "cs"},
+		queryResult: map[string]string{
+			"status": "New",
+		},
+	}, {
+		given: "Roles are Client and Ops",
+		when:  "neither ?created_by nor ?assignee is given", then: "is not allowed",
+		req:     newRequest("http://api.example.com/inquiries"),
+		roles:   []string{"client", "ops"},
+		wantErr: true,
+	}, {
+		given: "No role is given",
+		when:  "query is not given", then: "is not allowed",
+		req:     newRequest("http://api.example.com/inquiries"),
+		wantErr: true,
+	}}
 	for _, tt := range tests {
 		t.Run(tt.given, func(t *testing.T) {
-			req := tt.args.req()
-			got := rbo.Authorize(req, tt.args.role, tt.args.resource, tt.args.endpoint)
+			got := rbo.AuthorizeAny(tt.req, tt.roles, "inquiry", "get")
 			if tt.wantErr {
 				assert.Error(t, got, "when: %s, then: %s", tt.when, tt.then)
 			} else {
 				assert.NoError(t, got, "when: %s, then: %s", tt.when, tt.then)
+				assert.Equal(t, len(tt.queryResult), len(tt.req.URL.Query()), "when: %s, then: %s", tt.when, tt.then)
 				for key, val := range tt.queryResult {
-					assert.Equal(t, val, req.URL.Query().Get(key), "when: %s, then: %s", tt.when, tt.then)
+					assert.Equal(t, val, tt.req.URL.Query().Get(key), "when: %s, then: %s", tt.when, tt.then)
 				}
 			}
 		})
 	}
 }
+
+func TestRBAC_AuthorizeAnyBody(t *testing.T) {
+	rbo, err := rbac.FromBytes([]byte(`
+seller:
+  listing:
+    create:
+      allow: true
+      ensure:
+        body:
+          - key: price
+            operator: "="
+            value: "100"
+wholesaler:
+  listing:
+    create:
+      allow: true
+      ensure:
+        body:
+          - key: qty
+            operator: "="
+            value: "50"
+admin:
+  listing:
+    create:
+      allow: true
+`))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		given, then string
+		roles       []string
+		wantErr     bool
+	}{{
+		given: "Roles are Seller and Wholesaler", then: "Seller fails the body rule, but Wholesaler still reads the body and is allowed",
+		roles: []string{"seller", "wholesaler"},
+	}, {
+		given: "Roles are Seller and Admin", then: "Admin without body rule is allowed, and the body is intact",
+		roles: []string{"seller", "admin"},
+	}, {
+		given: "Role is Seller", then: "is not allowed, and the body is intact",
+		roles:   []string{"seller"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			body := `{"price": 90, "qty": 50}`
+			req, _ := http.NewRequest("POST", "http://api.example.com/listings", strings.NewReader(body))
+
+			got := rbo.AuthorizeAny(req, tt.roles, "listing", "create")
+			if tt.wantErr {
+				assert.Error(t, got, tt.then)
+			} else {
+				assert.NoError(t, got, tt.then)
+			}
+
+			// body is left for the downstream handler
+			b, _ := ioutil.ReadAll(req.Body)
+			assert.Equal(t, body, string(b), tt.then)
+		})
+	}
+}
+
+func TestRBAC_AuthorizeRequest(t *testing.T) {
+	rbo := rbac.FromFile("./test.yaml")
+	tests := []struct {
+		given, when, then string
+		ctx               func() context.Context
+		url               string
+		wantErr           bool
+		want              error
+	}{{
+		given: "ctx.role = client & ctx.email = client.one@email.com",
+		when:  "?created_by=client.one@email.com", then: "is allowed",
+		url: "http://api.example.com/inquiries?created_by=client.one@email.com",
+		ctx: func() context.Context {
+			ctx := context.WithValue(context.Background(), rbac.ContextKeyRole, "client")
+			return context.WithValue(ctx, rbac.ContextKeyEmail, "client.one@email.com")
+		},
+	}, {
+		given: "ctx.role = ops & ctx.email = ops.one@company.com",
+		when:  "?created_by=client.one@email.com", then: "is not allowed",
+		url: "http://api.example.com/inquiries?created_by=client.one@email.com",
+		ctx: func() context.Context {
+			ctx := context.WithValue(context.Background(), rbac.ContextKeyRole, "ops")
+			return context.WithValue(ctx, rbac.ContextKeyEmail, "ops.one@company.com")
+		},
+		wantErr: true,
+	}, {
+		given: "ctx.role does not exists",
+		when:  "?created_by=client.one@email.com", then: "returns ErrNoRole",
+		url: "http://api.example.com/inquiries?created_by=client.one@email.com",
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKeyEmail, "client.one@email.com")
+		},
+		wantErr: true,
+		want:    rbac.ErrNoRole,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			req, _ := http.NewRequest("", tt.url, nil)
+			req = req.WithContext(tt.ctx())
+
+			got := rbo.AuthorizeRequest(req, "inquiry", "get")
+			if tt.wantErr {
+				assert.Error(t, got, "when: %s, then: %s", tt.when, tt.then)
+				if tt.want != nil {
+					assert.Equal(t, tt.want, got, "when: %s, then: %s", tt.when, tt.then)
+				}
+			} else {
+				assert.NoError(t, got, "when: %s, then: %s", tt.when, tt.then)
+			}
+		})
+	}
+}
+
+func TestRBAC_AuthorizeUnknown(t *testing.T) {
+	rbo := rbac.FromFile("./test.yaml")
+	tests := []struct {
+		given, then string
+		role        string
+		resource    string
+		endpoint    string
+		want        error
+	}{{
+		given: "Role is unknown", then: "returns ErrRoleUnknown",
+		role: "stranger", resource: "inquiry", endpoint: "get",
+		want: rbac.ErrRoleUnknown,
+	}, {
+		given: "Resource is unknown", then: "returns ErrResourceUnknown",
+		role: "client", resource: "invoice", endpoint: "get",
+		want: rbac.ErrResourceUnknown,
+	}, {
+		given: "Endpoint is unknown", then: "returns ErrEndpointUnknown",
+		role: "client", resource: "inquiry", endpoint: "archive",
+		want: rbac.ErrEndpointUnknown,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			req, _ := http.NewRequest("", "http://api.example.com/inquiries", nil)
+
+			assert.Equal(t, tt.want, rbo.Authorize(req, tt.role, tt.resource, tt.endpoint), tt.then)
+			assert.Equal(t, tt.want, rbo.Compile().Authorize(req, tt.role, tt.resource, tt.endpoint), tt.then)
+		})
+	}
+}
+
+func TestRBAC_AuthorizeWildcard(t *testing.T) {
+	rbo, err := rbac.FromBytes([]byte(`
+admin:
+  "*":
+    "*":
+      allow: true
+    purge:
+      allow: false
+  invoice:
+    "*":
+      allow: true
+    delete:
+      allow: false
+auditor:
+  "*":
+    get:
+      allow: true
+`))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		given, then string
+		role        string
+		resource    string
+		endpoint    string
+		want        error
+	}{{
+		given: "Admin has */* wildcard", then: "is allowed on anything",
+		role: "admin", resource: "inquiry", endpoint: "assign",
+	}, {
+		given: "Admin has invoice/* wildcard", then: "is allowed on any invoice endpoint",
+		role: "admin", resource: "invoice", endpoint: "get",
+	}, {
+		given: "Admin has exact invoice/delete rule", then: "exact rule overrides the wildcard",
+		role: "admin", resource: "invoice", endpoint: "delete",
+		want: rbac.ErrForbidden,
+	}, {
+		given: "Admin has resource/* and */purge", then: "resource wildcard is preferred over endpoint wildcard",
+		role: "admin", resource: "invoice", endpoint: "purge",
+	}, {
+		given: "Admin has */purge rule", then: "endpoint rule overrides */* wildcard",
+		role: "admin", resource: "inquiry", endpoint: "purge",
+		want: rbac.ErrForbidden,
+	}, {
+		given: "Auditor has */get wildcard", then: "is allowed to get anything",
+		role: "auditor", resource: "inquiry", endpoint: "get",
+	}, {
+		given: "Auditor has */get wildcard", then: "returns ErrEndpointUnknown on other endpoints",
+		role: "auditor", resource: "inquiry", endpoint: "create",
+		want: rbac.ErrEndpointUnknown,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			req, _ := http.NewRequest("", "http://api.example.com/", nil)
+
+			assert.Equal(t, tt.want, rbo.Authorize(req, tt.role, tt.resource, tt.endpoint), tt.then)
+			assert.Equal(t, tt.want, rbo.Compile().Authorize(req, tt.role, tt.resource, tt.endpoint), tt.then)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package rbac
+
+import (
+	"net/http"
+)
+
+// permissionKey is a flattened {role, resource, endpoint} lookup key
+type permissionKey struct {
+	role     string
+	resource string
+	endpoint string
+}
+
+// CompiledRBAC is a flattened RBAC, where permission lookup is done once instead of nested 3 times
+type CompiledRBAC struct {
+	// Audit is an optional hook, invoked with every authorization decision before Authorize returns
+	Audit AuditFunc
+
+	permissions map[permissionKey]Permission
+	known       map[permissionKey]bool // known {role} and {role, resource}, to tell which level is missing
+}
+
+// Compile flattens rbac into a single level map of {role/resource/endpoint: permission}
+// The compiled result does not follow any change made to rbac afterward
+func (rbac RBAC) Compile() *CompiledRBAC {
+	compiled := &CompiledRBAC{
+		permissions: map[permissionKey]Permission{},
+		known:       map[permissionKey]bool{},
+	}
+
+	for role, resources := range rbac {
+		compiled.known[permissionKey{role: role}] = true
+		for resource, endpoints := range resources {
+			compiled.known[permissionKey{role: role, resource: resource}] = true
+			for endpoint, permission := range endpoints {
+				key := permissionKey{role, resource, endpoint}
+				compiled.permissions[key] = permission
+			}
+		}
+	}
+
+	return compiled
+}
+
+// Authorize a request based on its role, resource, and endpoint
+func (compiled *CompiledRBAC) Authorize(r *http.Request, role, resource, endpoint string) error {
+	err := compiled.authorize(r, role, resource, endpoint)
+	if compiled.Audit != nil {
+		compiled.Audit(AuditEvent{
+			Role:     role,
+			Resource: resource,
+			Endpoint: endpoint,
+			Allowed:  err == nil,
+			Err:      err,
+		})
+	}
+
+	return err
+}
+
+func (compiled *CompiledRBAC) authorize(r *http.Request, role, resource, endpoint string) error {
+	// exact entries are preferred over wildcard, see Resource.lookup
+	keys := [4]permissionKey{
+		{role, resource, endpoint},
+		{role, resource, Wildcard},
+		{role, Wildcard, endpoint},
+		{role, Wildcard, Wildcard},
+	}
+	for _, key := range keys {
+		if permission, exists := compiled.permissions[key]; exists {
+			return permission.authorize(r)
+		}
+	}
+
+	switch {
+	case !compiled.known[permissionKey{role: role}]:
+		return ErrRoleUnknown
+	case !compiled.known[permissionKey{role: role, resource: resource}] &&
+		!compiled.known[permissionKey{role: role, resource: Wildcard}]:
+		return ErrResourceUnknown
+	default:
+		return ErrEndpointUnknown
+	}
+}
@@ -0,0 +1,96 @@
+package rbac_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bastianrob/go-experiences/rbac"
+)
+
+func TestCompiledRBAC_Authorize(t *testing.T) {
+	rbo := rbac.FromFile("./test.yaml")
+	compiled := rbo.Compile()
+
+	tests := []struct {
+		given, when, then string
+		url               string
+		role              string
+		resource          string
+		endpoint          string
+	}{{
+		given: "Role is Client & email = client.one@email.com",
+		when:  "?created_by=client.one@email.com", then: "is allowed",
+		url:  "http://api.example.com/inquiries?created_by=client.one@email.com",
+		role: "client", resource: "inquiry", endpoint: "get",
+	}, {
+		given: "Role is Client & email = client.one@email.com",
+		when:  "?created_by=client.other@email.com", then: "is not allowed",
+		url:  "http://api.example.com/inquiries?created_by=client.other@email.com",
+		role: "client", resource: "inquiry", endpoint: "get",
+	}, {
+		given: "Role is CS",
+		when:  "?status=Assigned", then: "status=New is enforced",
+		url:  "http://api.example.com/inquiries?status=Assigned",
+		role: "cs", resource: "inquiry", endpoint: "get",
+	}, {
+		given: "Role is Manager",
+		when:  "trying to create", then: "is not allowed",
+		url:  "http://api.example.com/inquiries",
+		role: "manager", resource: "inquiry", endpoint: "create",
+	}, {
+		given: "Role is unknown",
+		when:  "trying to get", then: "is not allowed",
+		url:  "http://api.example.com/inquiries",
+		role: "stranger", resource: "inquiry", endpoint: "get",
+	}, {
+		given: "Resource is unknown",
+		when:  "trying to get", then: "is not allowed",
+		url:  "http://api.example.com/invoices",
+		role: "client", resource: "invoice", endpoint: "get",
+	}, {
+		given: "Endpoint is unknown",
+		when:  "trying to archive", then: "is not allowed",
+		url:  "http://api.example.com/inquiries",
+		role: "client", resource: "inquiry", endpoint: "archive",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), rbac.ContextKey("email"), "client.one@email.com")
+			want, _ := http.NewRequest("", tt.url, nil)
+			want = want.WithContext(ctx)
+			got, _ := http.NewRequest("", tt.url, nil)
+			got = got.WithContext(ctx)
+
+			// compiled rbac must behave exactly like the nested one
+			wantErr := rbo.Authorize(want, tt.role, tt.resource, tt.endpoint)
+			gotErr := compiled.Authorize(got, tt.role, tt.resource, tt.endpoint)
+			assert.Equal(t, wantErr, gotErr, "when: %s, then: %s", tt.when, tt.then)
+			assert.Equal(t, want.URL.String(), got.URL.String(), "when: %s, then: %s", tt.when, tt.then)
+		})
+	}
+}
+
+func BenchmarkRBAC_Authorize(b *testing.B) {
+	rbo := rbac.FromFile("./test.yaml")
+	req, _ := http.NewRequest("POST", "http://api.example.com/inquiries/INQ-0001/assign", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		rbo.Authorize(req, "manager", "inquiry", "assign")
+	}
+}
+
+func BenchmarkCompiledRBAC_Authorize(b *testing.B) {
+	compiled := rbac.FromFile("./test.yaml").Compile()
+	req, _ := http.NewRequest("POST", "http://api.example.com/inquiries/INQ-0001/assign", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		compiled.Authorize(req, "manager", "inquiry", "assign")
+	}
+}
@@ -1,5 +1,7 @@
 package rbac
 
+import "context"
+
 // Collection of accepted RBAC context
 const (
 	ContextKeyRole  = ContextKey("role")
@@ -12,3 +14,14 @@ type ContextKey string
 func (c ContextKey) String() string {
 	return "rbac_context_" + string(c)
 }
+
+// RoleFromContext get role stored in ctx under ContextKeyRole
+// returns false if role does not exists, or is not a non-empty string
+func RoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(ContextKeyRole).(string)
+	if !ok || role == "" {
+		return "", false
+	}
+
+	return role, true
+}
@@ -0,0 +1,49 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bastianrob/go-experiences/rbac"
+)
+
+func TestRoleFromContext(t *testing.T) {
+	tests := []struct {
+		given  string
+		then   string
+		ctx    func() context.Context
+		want   string
+		wantOk bool
+	}{{
+		given: "ctx.role = client", then: "role must be client",
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKeyRole, "client")
+		},
+		want:   "client",
+		wantOk: true,
+	}, {
+		given: "ctx.role does not exists", then: "role must not be found",
+		ctx: func() context.Context {
+			return context.Background()
+		},
+	}, {
+		given: "ctx.role is empty", then: "role must not be found",
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKeyRole, "")
+		},
+	}, {
+		given: "ctx.role is not a string", then: "role must not be found",
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKeyRole, []string{"client"})
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			got, ok := rbac.RoleFromContext(tt.ctx())
+			assert.Equal(t, tt.wantOk, ok, tt.then)
+			assert.Equal(t, tt.want, got, tt.then)
+		})
+	}
+}
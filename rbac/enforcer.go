@@ -1,6 +1,7 @@
 package rbac
 
 import (
+	"fmt"
 	"net/http"
 )
 
@@ -9,19 +10,81 @@ type Enforcer Ensurer
 
 // QueryComplies enforce query request from rule
 func (enf Enforcer) QueryComplies(r *http.Request) error {
+	if enf.Query == nil || len(enf.Query) <= 0 {
+		return nil
+	}
+
 	q := r.URL.Query()
 	ctx := r.Context()
 	for _, rule := range enf.Query {
-		expected := rule.FromContext(ctx)
-		valueStr, isString := expected.(string)
-		if !isString {
-			return ErrNotString
+		expected, err := rule.FromContextSafe(ctx)
+		if err != nil {
+			return err
+		}
+		valueStr, err := stringify(expected)
+		if err != nil {
+			return err
 		}
 
-		q.Set(rule.Key, valueStr)
+		if rule.Append {
+			q.Add(rule.Key, valueStr)
+		} else {
+			q.Set(rule.Key, valueStr)
+		}
 	}
 
 	r.URL.RawQuery = q.Encode()
 	// all query enforced with rules
 	return nil
 }
+
+// HeaderComplies enforce request header from rule
+func (enf Enforcer) HeaderComplies(r *http.Request) error {
+	if enf.Header == nil || len(enf.Header) <= 0 {
+		return nil
+	}
+
+	ctx := r.Context()
+	for _, rule := range enf.Header {
+		expected, err := rule.FromContextSafe(ctx)
+		if err != nil {
+			return err
+		}
+		valueStr, err := stringify(expected)
+		if err != nil {
+			return err
+		}
+
+		if rule.Append {
+			r.Header.Add(rule.Key, valueStr)
+		} else {
+			r.Header.Set(rule.Key, valueStr)
+		}
+	}
+
+	// all header enforced with rules
+	return nil
+}
+
+// PathComplies check whether request path complies with rule
+// Rewriting a request path is rarely what we want, so path rules are ensured instead of enforced
+func (enf Enforcer) PathComplies(r *http.Request) error {
+	return Ensurer(enf).PathComplies(r)
+}
+
+// stringify an enforced value, which can be a string, a fmt.Stringer, or a basic numeric / boolean type
+// Returns ErrNotString for any other value
+func stringify(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case fmt.Stringer:
+		return v.String(), nil
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64, bool:
+		return fmt.Sprint(v), nil
+	}
+
+	return "", ErrNotString
+}
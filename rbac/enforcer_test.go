@@ -2,6 +2,7 @@ package rbac_test
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -9,6 +10,13 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// tenantID is a fmt.Stringer stored in context
+type tenantID int
+
+func (id tenantID) String() string {
+	return fmt.Sprintf("TNT-%03d", int(id))
+}
+
 func TestEnforcer_QueryComplies(t *testing.T) {
 	type args struct {
 		method string
@@ -43,6 +51,70 @@ func TestEnforcer_QueryComplies(t *testing.T) {
 			"id":   "0001",
 			"name": "John",
 		},
+	}, {
+		given: "Query: name=nil and Rule: name=ctx.user.name and ctx.user is not a map",
+		then:  "QueryComplies must return error instead of panic",
+		args: args{
+			url: "http://api.example.com/resources?name=nil",
+		},
+		enforcer: rbac.Enforcer{
+			Query: []rbac.Rule{
+				{Key: "name", Value: "ctx.user.name"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("user"), "John")
+		},
+		wantErr: true,
+	}, {
+		given: "Query: tenant=nil and Rule: tenant=ctx.tenant and ctx.tenant=42 as int",
+		then:  "QueryComplies must not return error, and int must be enforced as string",
+		args: args{
+			url: "http://api.example.com/resources?tenant=nil",
+		},
+		enforcer: rbac.Enforcer{
+			Query: []rbac.Rule{
+				{Key: "tenant", Value: "ctx.tenant"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("tenant"), 42)
+		},
+		want: map[string]string{
+			"tenant": "42",
+		},
+	}, {
+		given: "Query: tenant=nil and Rule: tenant=ctx.tenant and ctx.tenant is a fmt.Stringer",
+		then:  "QueryComplies must not return error, and stringer must be enforced as its String()",
+		args: args{
+			url: "http://api.example.com/resources?tenant=nil",
+		},
+		enforcer: rbac.Enforcer{
+			Query: []rbac.Rule{
+				{Key: "tenant", Value: "ctx.tenant"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("tenant"), tenantID(42))
+		},
+		want: map[string]string{
+			"tenant": "TNT-042",
+		},
+	}, {
+		given: "Query: tenant=nil and Rule: tenant=ctx.tenant and ctx.tenant is a map",
+		then:  "QueryComplies must return ErrNotString",
+		args: args{
+			url: "http://api.example.com/resources?tenant=nil",
+		},
+		enforcer: rbac.Enforcer{
+			Query: []rbac.Rule{
+				{Key: "tenant", Value: "ctx.tenant"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("tenant"), map[string]interface{}{"id": 42})
+		},
+		wantErr: true,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.given, func(t *testing.T) {
@@ -62,3 +134,117 @@ func TestEnforcer_QueryComplies(t *testing.T) {
 		})
 	}
 }
+
+func TestEnforcer_HeaderComplies(t *testing.T) {
+	type args struct {
+		method string
+		url    string
+		header map[string]string
+	}
+	tests := []struct {
+		given    string
+		then     string
+		enforcer rbac.Enforcer
+		context  func() context.Context
+		args     args
+		want     map[string]string
+		wantErr  bool
+	}{{
+		given: "Header: X-Tenant-ID=nil and Rule: X-Tenant-ID=ctx.tenant and ctx.tenant=TNT-0001",
+		then:  "HeaderComplies must not return error, and header must be re-written by enforcer",
+		args: args{
+			url:    "http://api.example.com/resources",
+			header: map[string]string{"X-Tenant-ID": "nil"},
+		},
+		enforcer: rbac.Enforcer{
+			Header: []rbac.Rule{
+				{Key: "X-Tenant-ID", Value: "ctx.tenant"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("tenant"), "TNT-0001")
+		},
+		want: map[string]string{
+			"X-Tenant-ID": "TNT-0001",
+		},
+	}, {
+		given: "Header: X-Tenant-ID is not given and Rule: X-Tenant-ID=ctx.tenant and ctx.tenant is not a string",
+		then:  "HeaderComplies must return error",
+		args: args{
+			url: "http://api.example.com/resources",
+		},
+		enforcer: rbac.Enforcer{
+			Header: []rbac.Rule{
+				{Key: "X-Tenant-ID", Value: "ctx.tenant"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("tenant"), struct{}{})
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			r, _ := http.NewRequest(tt.args.method, tt.args.url, nil)
+			r = r.WithContext(tt.context())
+			for key, val := range tt.args.header {
+				r.Header.Set(key, val)
+			}
+
+			err := tt.enforcer.HeaderComplies(r)
+			if tt.wantErr {
+				assert.Error(t, err, tt.given)
+			} else {
+				assert.NoError(t, err, tt.given)
+				for key, val := range tt.want {
+					assert.Equal(t, val, r.Header.Get(key), tt.then)
+				}
+			}
+		})
+	}
+}
+
+func TestEnforcer_QueryComplies_Append(t *testing.T) {
+	tests := []struct {
+		given    string
+		then     string
+		enforcer rbac.Enforcer
+		url      string
+		want     map[string][]string
+	}{{
+		given: "Query: tenant=Y and Rule: append tenant=X",
+		then:  "tenant=X must be added while tenant=Y is left intact",
+		url:   "http://api.example.com/resources?tenant=Y",
+		enforcer: rbac.Enforcer{
+			Query: []rbac.Rule{
+				{Key: "tenant", Value: "X", Append: true},
+			},
+		},
+		want: map[string][]string{
+			"tenant": {"Y", "X"},
+		},
+	}, {
+		given: "Query: tenant=Y and Rule: tenant=X",
+		then:  "tenant=Y must be overwritten by tenant=X",
+		url:   "http://api.example.com/resources?tenant=Y",
+		enforcer: rbac.Enforcer{
+			Query: []rbac.Rule{
+				{Key: "tenant", Value: "X"},
+			},
+		},
+		want: map[string][]string{
+			"tenant": {"X"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			r, _ := http.NewRequest("", tt.url, nil)
+
+			err := tt.enforcer.QueryComplies(r)
+			assert.NoError(t, err, tt.given)
+			for key, val := range tt.want {
+				assert.Equal(t, val, r.URL.Query()[key], tt.then)
+			}
+		})
+	}
+}
@@ -1,16 +1,25 @@
 package rbac
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
 )
 
 // Ensurer data model
-// Can either ensure query, header, or path
+// Can either ensure query, header, path, or json body
 type Ensurer struct {
-	Query  []Rule `yaml:"query"`
-	Header []Rule `yaml:"header"`
-	Path   []Rule `yaml:"path"`
+	Query  []Rule `yaml:"query" json:"query,omitempty"`
+	Header []Rule `yaml:"header" json:"header,omitempty"`
+	Path   []Rule `yaml:"path" json:"path,omitempty"`
+	Body   []Rule `yaml:"body,omitempty" json:"body,omitempty"`
+
+	// Pattern is the path template used to extract named path segments for Path rules
+	// e.g: '/inquiries/:id/assign', see PathParams for the template syntax
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty"`
 }
 
 // QueryComplies check whether query request complies with rules
@@ -20,10 +29,27 @@ func (ens Ensurer) QueryComplies(r *http.Request) error {
 	}
 
 	ctx := r.Context()
+	query := r.URL.Query()
 	for _, rule := range ens.Query {
-		actual := r.URL.Query().Get(rule.Key)
-		expected := rule.FromContext(ctx)
+		expected, err := rule.FromContextSafe(ctx)
+		if err != nil {
+			return err
+		}
+
+		if rule.Multi {
+			actuals := query[rule.Key]
+			if !rule.ComplyValues(expected, actuals) {
+				mode := rule.MultiMode
+				if mode == "" {
+					mode = MultiAll
+				}
+				return fmt.Errorf("Query rule violation: ensure %s '%s' %s '%v', instead got: '%v'",
+					mode, rule.Key, rule.Operator, expected, actuals)
+			}
+			continue
+		}
 
+		actual := query.Get(rule.Key)
 		if !rule.Comply(expected, actual) {
 			return fmt.Errorf("Query rule violation: ensure '%s' %s '%v', instead got: '%s'",
 				rule.Key, rule.Operator, expected, actual)
@@ -33,3 +59,123 @@ func (ens Ensurer) QueryComplies(r *http.Request) error {
 	// all query complies with rules
 	return nil
 }
+
+// HeaderComplies check whether request header complies with rules
+func (ens Ensurer) HeaderComplies(r *http.Request) error {
+	if ens.Header == nil || len(ens.Header) <= 0 {
+		return nil
+	}
+
+	ctx := r.Context()
+	for _, rule := range ens.Header {
+		actual := r.Header.Get(rule.Key)
+		expected, err := rule.FromContextSafe(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !rule.Comply(expected, actual) {
+			return fmt.Errorf("Header rule violation: ensure '%s' %s '%v', instead got: '%s'",
+				rule.Key, rule.Operator, expected, actual)
+		}
+	}
+
+	// all header complies with rules
+	return nil
+}
+
+// PathComplies check whether request path complies with rules
+// Named path segments are extracted using ens.Pattern, and rule.Key refers to the segment name without ':'
+func (ens Ensurer) PathComplies(r *http.Request) error {
+	if ens.Path == nil || len(ens.Path) <= 0 {
+		return nil
+	}
+
+	params, match := PathParams(ens.Pattern, r.URL.Path)
+	if !match {
+		return fmt.Errorf("Path rule violation: path '%s' does not match pattern '%s'",
+			r.URL.Path, ens.Pattern)
+	}
+
+	ctx := r.Context()
+	for _, rule := range ens.Path {
+		actual := params[rule.Key]
+		expected, err := rule.FromContextSafe(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !rule.Comply(expected, actual) {
+			return fmt.Errorf("Path rule violation: ensure '%s' %s '%v', instead got: '%s'",
+				rule.Key, rule.Operator, expected, actual)
+		}
+	}
+
+	// all path segment complies with rules
+	return nil
+}
+
+// BodyComplies check whether json request body complies with rules
+// rule.Key is a dotted path to the body field, e.g: 'tenant.id' refers to {"tenant": {"id": "..."}}
+// The body is restored after being read, so it can still be read by the next handler
+func (ens Ensurer) BodyComplies(r *http.Request) error {
+	if ens.Body == nil || len(ens.Body) <= 0 {
+		return nil
+	}
+
+	var b []byte
+	if r.Body != nil {
+		var err error
+		b, err = ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+	var body interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber() // keep numbers as they are written, instead of float64
+	if err := decoder.Decode(&body); err != nil {
+		return fmt.Errorf("Body rule violation: body is not a valid json: %v", err)
+	}
+
+	ctx := r.Context()
+	for _, rule := range ens.Body {
+		actual := bodyField(body, rule.Key)
+		expected, err := rule.FromContextSafe(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !rule.Comply(expected, actual) {
+			return fmt.Errorf("Body rule violation: ensure '%s' %s '%v', instead got: '%v'",
+				rule.Key, rule.Operator, expected, actual)
+		}
+	}
+
+	// all body field complies with rules
+	return nil
+}
+
+// bodyField get the value of a dotted path in a decoded json body
+// Numbers are returned as their string literal, so they can be compared against rule value
+// Returns nil if the path does not exist
+func bodyField(body interface{}, path string) interface{} {
+	field := body
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := field.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		field = obj[key]
+	}
+
+	if num, ok := field.(json.Number); ok {
+		return num.String()
+	}
+
+	return field
+}
@@ -2,7 +2,9 @@ package rbac_test
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,6 +41,46 @@ func TestEnsurer_QueryComplies(t *testing.T) {
 			// we give the context.name = "John"
 			return context.WithValue(context.Background(), rbac.ContextKey("name"), "John")
 		},
+	}, {
+		given: "Query: name=John and Rule: name=ctx.user.name and ctx.user is not a map",
+		then:  "QueryComplies must return error instead of panic",
+		args: args{
+			url: "http://api.example.com/resources?name=John",
+		},
+		ensurer: rbac.Ensurer{
+			Query: []rbac.Rule{
+				{Key: "name", Operator: "=", Value: "ctx.user.name"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("user"), "John")
+		},
+		wantErr: true,
+	}, {
+		given: "Query: id=0001 and Rule: internal_notes is absent",
+		then:  "QueryComplies must not return error",
+		args: args{
+			url: "http://api.example.com/resources?id=0001",
+		},
+		ensurer: rbac.Ensurer{
+			Query: []rbac.Rule{
+				{Key: "internal_notes", Operator: "absent"},
+			},
+		},
+		context: context.Background,
+	}, {
+		given: "Query: internal_notes=secret and Rule: internal_notes is absent",
+		then:  "QueryComplies must return error",
+		args: args{
+			url: "http://api.example.com/resources?internal_notes=secret",
+		},
+		ensurer: rbac.Ensurer{
+			Query: []rbac.Rule{
+				{Key: "internal_notes", Operator: "absent"},
+			},
+		},
+		context: context.Background,
+		wantErr: true,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.given, func(t *testing.T) {
@@ -54,3 +96,261 @@ func TestEnsurer_QueryComplies(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsurer_HeaderComplies(t *testing.T) {
+	type args struct {
+		method string
+		url    string
+		header map[string]string
+	}
+	tests := []struct {
+		given   string
+		then    string
+		ensurer rbac.Ensurer
+		context func() context.Context
+		args    args
+		wantErr bool
+	}{{
+		given: "Header: X-Tenant-ID=TNT-0001 and Rule: X-Tenant-ID=ctx.tenant and ctx.tenant=TNT-0001",
+		then:  "HeaderComplies must not return error",
+		args: args{
+			url:    "http://api.example.com/resources",
+			header: map[string]string{"X-Tenant-ID": "TNT-0001"},
+		},
+		ensurer: rbac.Ensurer{
+			Header: []rbac.Rule{
+				{Key: "X-Tenant-ID", Operator: "=", Value: "ctx.tenant"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("tenant"), "TNT-0001")
+		},
+	}, {
+		given: "Header: X-Tenant-ID=TNT-0002 and Rule: X-Tenant-ID=ctx.tenant and ctx.tenant=TNT-0001",
+		then:  "HeaderComplies must return error",
+		args: args{
+			url:    "http://api.example.com/resources",
+			header: map[string]string{"X-Tenant-ID": "TNT-0002"},
+		},
+		ensurer: rbac.Ensurer{
+			Header: []rbac.Rule{
+				{Key: "X-Tenant-ID", Operator: "=", Value: "ctx.tenant"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("tenant"), "TNT-0001")
+		},
+		wantErr: true,
+	}, {
+		given: "Header: X-Tenant-ID is not given and Rule: X-Tenant-ID=ctx.tenant and ctx.tenant=TNT-0001",
+		then:  "HeaderComplies must return error",
+		args: args{
+			url: "http://api.example.com/resources",
+		},
+		ensurer: rbac.Ensurer{
+			Header: []rbac.Rule{
+				{Key: "X-Tenant-ID", Operator: "=", Value: "ctx.tenant"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("tenant"), "TNT-0001")
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			r, _ := http.NewRequest(tt.args.method, tt.args.url, nil)
+			r = r.WithContext(tt.context())
+			for key, val := range tt.args.header {
+				r.Header.Set(key, val)
+			}
+
+			err := tt.ensurer.HeaderComplies(r)
+			if tt.wantErr {
+				assert.Error(t, err, tt.given)
+			} else {
+				assert.NoError(t, err, tt.given)
+			}
+		})
+	}
+}
+
+func TestEnsurer_PathComplies(t *testing.T) {
+	type args struct {
+		method string
+		url    string
+	}
+	tests := []struct {
+		given   string
+		then    string
+		ensurer rbac.Ensurer
+		context func() context.Context
+		args    args
+		wantErr bool
+	}{{
+		given: "Path: /inquiries/INQ-0001/assign and Rule: id=ctx.inquiry and ctx.inquiry=INQ-0001",
+		then:  "PathComplies must not return error",
+		args: args{
+			url: "http://api.example.com/inquiries/INQ-0001/assign",
+		},
+		ensurer: rbac.Ensurer{
+			Pattern: "/inquiries/:id/assign",
+			Path: []rbac.Rule{
+				{Key: "id", Operator: "=", Value: "ctx.inquiry"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("inquiry"), "INQ-0001")
+		},
+	}, {
+		given: "Path: /inquiries/INQ-0002/assign and Rule: id=ctx.inquiry and ctx.inquiry=INQ-0001",
+		then:  "PathComplies must return error",
+		args: args{
+			url: "http://api.example.com/inquiries/INQ-0002/assign",
+		},
+		ensurer: rbac.Ensurer{
+			Pattern: "/inquiries/:id/assign",
+			Path: []rbac.Rule{
+				{Key: "id", Operator: "=", Value: "ctx.inquiry"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("inquiry"), "INQ-0001")
+		},
+		wantErr: true,
+	}, {
+		given: "Path: /inquiries/INQ-0001/close and Pattern: /inquiries/:id/assign",
+		then:  "PathComplies must return error",
+		args: args{
+			url: "http://api.example.com/inquiries/INQ-0001/close",
+		},
+		ensurer: rbac.Ensurer{
+			Pattern: "/inquiries/:id/assign",
+			Path: []rbac.Rule{
+				{Key: "id", Operator: "=", Value: "ctx.inquiry"},
+			},
+		},
+		context: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("inquiry"), "INQ-0001")
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			r, _ := http.NewRequest(tt.args.method, tt.args.url, nil)
+			r = r.WithContext(tt.context())
+
+			err := tt.ensurer.PathComplies(r)
+			if tt.wantErr {
+				assert.Error(t, err, tt.given)
+			} else {
+				assert.NoError(t, err, tt.given)
+			}
+		})
+	}
+}
+
+func TestEnsurer_BodyComplies(t *testing.T) {
+	tests := []struct {
+		given   string
+		then    string
+		body    string
+		wantErr bool
+	}{{
+		given: `Body: {"tenant": {"id": "TNT-001"}, "seats": 3} and Rule: tenant.id=ctx.tenant and ctx.tenant=TNT-001`,
+		then:  "BodyComplies must not return error",
+		body:  `{"tenant": {"id": "TNT-001"}, "seats": 3}`,
+	}, {
+		given:   `Body: {"tenant": {"id": "TNT-002"}, "seats": 3} and Rule: tenant.id=ctx.tenant and ctx.tenant=TNT-001`,
+		then:    "BodyComplies must return error",
+		body:    `{"tenant": {"id": "TNT-002"}, "seats": 3}`,
+		wantErr: true,
+	}, {
+		given:   `Body: {"seats": 3} and Rule: tenant.id=ctx.tenant`,
+		then:    "BodyComplies must return error",
+		body:    `{"seats": 3}`,
+		wantErr: true,
+	}, {
+		given:   "Body is not a json",
+		then:    "BodyComplies must return error",
+		body:    `tenant=TNT-001`,
+		wantErr: true,
+	}}
+	ensurer := rbac.Ensurer{
+		Body: []rbac.Rule{
+			{Key: "tenant.id", Operator: "=", Value: "ctx.tenant"},
+			{Key: "seats", Operator: "=", Value: "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			r, _ := http.NewRequest("POST", "http://api.example.com/inquiries", strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(context.Background(), rbac.ContextKey("tenant"), "TNT-001"))
+
+			err := ensurer.BodyComplies(r)
+			if tt.wantErr {
+				assert.Error(t, err, tt.then)
+			} else {
+				assert.NoError(t, err, tt.then)
+			}
+
+			// body is restored for the next handler
+			b, _ := ioutil.ReadAll(r.Body)
+			assert.Equal(t, tt.body, string(b), tt.then)
+		})
+	}
+}
+
+func TestEnsurer_QueryComplies_Multi(t *testing.T) {
+	tests := []struct {
+		given   string
+		then    string
+		url     string
+		mode    string
+		wantErr bool
+	}{{
+		given: "Query: status=New&status=Assigned and Rule: all status != Closed",
+		then:  "QueryComplies must not return error",
+		url:   "http://api.example.com/inquiries?status=New&status=Assigned",
+		mode:  rbac.MultiAll,
+	}, {
+		given:   "Query: status=New&status=Closed and Rule: all status != Closed",
+		then:    "QueryComplies must return error",
+		url:     "http://api.example.com/inquiries?status=New&status=Closed",
+		mode:    rbac.MultiAll,
+		wantErr: true,
+	}, {
+		given:   "Query: status=New&status=Closed and Rule: status != Closed, with default mode",
+		then:    "QueryComplies must return error",
+		url:     "http://api.example.com/inquiries?status=New&status=Closed",
+		wantErr: true,
+	}, {
+		given: "Query: status=Closed&status=New and Rule: any status != Closed",
+		then:  "QueryComplies must not return error",
+		url:   "http://api.example.com/inquiries?status=Closed&status=New",
+		mode:  rbac.MultiAny,
+	}, {
+		given:   "Query: status=Closed&status=Closed and Rule: any status != Closed",
+		then:    "QueryComplies must return error",
+		url:     "http://api.example.com/inquiries?status=Closed&status=Closed",
+		mode:    rbac.MultiAny,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			ensurer := rbac.Ensurer{
+				Query: []rbac.Rule{
+					{Key: "status", Operator: "!=", Value: "Closed", Multi: true, MultiMode: tt.mode},
+				},
+			}
+			r, _ := http.NewRequest("", tt.url, nil)
+
+			err := ensurer.QueryComplies(r)
+			if tt.wantErr {
+				assert.Error(t, err, tt.then)
+			} else {
+				assert.NoError(t, err, tt.then)
+			}
+		})
+	}
+}
@@ -4,8 +4,14 @@ import "errors"
 
 // Error collection
 var (
-	ErrNotString   = errors.New("Expected value is not a string")
-	ErrNoRole      = errors.New("You have no role assigned to you")
-	ErrRoleUnknown = errors.New("You have an unknown role assigned to you")
-	ErrForbidden   = errors.New("You are not allowed to access specified resource")
+	ErrNotString          = errors.New("Expected value is not a string")
+	ErrNoRole             = errors.New("You have no role assigned to you")
+	ErrRoleUnknown        = errors.New("You have an unknown role assigned to you")
+	ErrResourceUnknown    = errors.New("Resource is unknown to your role")
+	ErrEndpointUnknown    = errors.New("Endpoint is unknown to your role")
+	ErrForbidden          = errors.New("You are not allowed to access specified resource")
+	ErrUnknownFormat      = errors.New("Config file format is not supported")
+	ErrInvalidContextPath = errors.New("Rule value is not a valid context path")
+	ErrOutsideActiveHours = errors.New("You are not allowed to access specified resource at this time")
+	ErrInvalidActiveHours = errors.New("Active hours is not a valid time of day")
 )
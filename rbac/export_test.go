@@ -0,0 +1,9 @@
+package rbac
+
+import "time"
+
+// SetNow replaces the clock of active hours, and returns a func to restore it
+func SetNow(fn func() time.Time) (restore func()) {
+	now = fn
+	return func() { now = time.Now }
+}
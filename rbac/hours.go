@@ -0,0 +1,66 @@
+package rbac
+
+import (
+	"strings"
+	"time"
+)
+
+// ClockLayout is the time of day layout of active hours
+const ClockLayout = "15:04"
+
+// now is the clock of active hours, replaced in tests
+var now = time.Now
+
+// ActiveHours restricts a permission into a daily time window, e.g: business hours or maintenance windows
+// A window which ends before it starts spans over midnight, e.g: 22:00 - 06:00
+type ActiveHours struct {
+	Start string   `yaml:"start" json:"start"`                   // inclusive time of day, in ClockLayout
+	End   string   `yaml:"end" json:"end"`                       // exclusive time of day, in ClockLayout
+	Days  []string `yaml:"days,omitempty" json:"days,omitempty"` // e.g: Mon or Monday, empty means every day
+}
+
+// Contains checks whether t is within active hours
+// Days of a window spanning over midnight refer to the day it starts
+func (hours ActiveHours) Contains(t time.Time) (bool, error) {
+	start, err := time.Parse(ClockLayout, hours.Start)
+	if err != nil {
+		return false, ErrInvalidActiveHours
+	}
+	end, err := time.Parse(ClockLayout, hours.End)
+	if err != nil {
+		return false, ErrInvalidActiveHours
+	}
+
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	clock := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	switch {
+	case from <= to:
+		if clock < from || clock >= to {
+			return false, nil
+		}
+	case clock >= from: // over midnight, before midnight
+	case clock < to: // over midnight, after midnight: the window started yesterday
+		day = (day + 6) % 7
+	default:
+		return false, nil
+	}
+
+	return hours.on(day), nil
+}
+
+// on checks whether active hours applies on a day of week
+func (hours ActiveHours) on(day time.Weekday) bool {
+	if len(hours.Days) <= 0 {
+		return true
+	}
+
+	for _, d := range hours.Days {
+		if strings.EqualFold(d, day.String()) || strings.EqualFold(d, day.String()[:3]) {
+			return true
+		}
+	}
+
+	return false
+}
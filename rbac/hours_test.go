@@ -0,0 +1,97 @@
+package rbac_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bastianrob/go-experiences/rbac"
+)
+
+func TestRBAC_AuthorizeActiveHours(t *testing.T) {
+	rbo, err := rbac.FromBytes([]byte(`
+cs:
+  inquiry:
+    assign:
+      allow: true
+      active_hours:
+        start: "09:00"
+        end: "17:00"
+        days: [Mon, Tue, Wed, Thu, Friday]
+ops:
+  inquiry:
+    migrate:
+      allow: true
+      active_hours:
+        start: "22:00"
+        end: "02:00"
+        days: [Sat]
+    broken:
+      allow: true
+      active_hours:
+        start: "9am"
+        end: "5pm"
+`))
+	assert.NoError(t, err)
+
+	// 2021-03-01 is a Monday
+	at := func(value string) time.Time {
+		t, _ := time.Parse("2006-01-02 15:04", value)
+		return t
+	}
+	tests := []struct {
+		given, then string
+		now         time.Time
+		role        string
+		endpoint    string
+		want        error
+	}{{
+		given: "Monday 09:00", then: "start of business hours is allowed",
+		now: at("2021-03-01 09:00"), role: "cs", endpoint: "assign",
+	}, {
+		given: "Friday 16:59", then: "end of business hours is allowed",
+		now: at("2021-03-05 16:59"), role: "cs", endpoint: "assign",
+	}, {
+		given: "Monday 17:00", then: "after business hours is not allowed",
+		now: at("2021-03-01 17:00"), role: "cs", endpoint: "assign",
+		want: rbac.ErrOutsideActiveHours,
+	}, {
+		given: "Monday 08:59", then: "before business hours is not allowed",
+		now: at("2021-03-01 08:59"), role: "cs", endpoint: "assign",
+		want: rbac.ErrOutsideActiveHours,
+	}, {
+		given: "Saturday 10:00", then: "weekend is not allowed",
+		now: at("2021-03-06 10:00"), role: "cs", endpoint: "assign",
+		want: rbac.ErrOutsideActiveHours,
+	}, {
+		given: "Saturday 23:00", then: "maintenance window before midnight is allowed",
+		now: at("2021-03-06 23:00"), role: "ops", endpoint: "migrate",
+	}, {
+		given: "Sunday 01:00", then: "maintenance window started on saturday is allowed",
+		now: at("2021-03-07 01:00"), role: "ops", endpoint: "migrate",
+	}, {
+		given: "Sunday 23:00", then: "maintenance window started on sunday is not allowed",
+		now: at("2021-03-07 23:00"), role: "ops", endpoint: "migrate",
+		want: rbac.ErrOutsideActiveHours,
+	}, {
+		given: "Saturday 12:00", then: "outside of maintenance window is not allowed",
+		now: at("2021-03-06 12:00"), role: "ops", endpoint: "migrate",
+		want: rbac.ErrOutsideActiveHours,
+	}, {
+		given: "Active hours is invalid", then: "returns ErrInvalidActiveHours",
+		now: at("2021-03-01 10:00"), role: "ops", endpoint: "broken",
+		want: rbac.ErrInvalidActiveHours,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			restore := rbac.SetNow(func() time.Time { return tt.now })
+			defer restore()
+
+			req, _ := http.NewRequest("", "http://api.example.com/inquiries", nil)
+			assert.Equal(t, tt.want, rbo.Authorize(req, tt.role, "inquiry", tt.endpoint), tt.then)
+			assert.Equal(t, tt.want, rbo.Compile().Authorize(req, tt.role, "inquiry", tt.endpoint), tt.then)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package rbac
+
+import (
+	"net/http"
+)
+
+// Extractor gets role, resource, and endpoint of an incoming request
+type Extractor func(r *http.Request) (role, resource, endpoint string)
+
+// Authorizer authorize a request based on its role, resource, and endpoint
+// Implemented by both *RBAC and *CompiledRBAC
+type Authorizer interface {
+	Authorize(r *http.Request, role, resource, endpoint string) error
+}
+
+// Middleware authorize every request with rbac before passing it to the next handler
+// Responds 401 when request have no role, 404 when resource or endpoint is unknown, or 403 when request is not authorized
+func Middleware(rbac Authorizer, extract Extractor) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, resource, endpoint := extract(r)
+			if role == "" {
+				http.Error(w, ErrNoRole.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			err := rbac.Authorize(r, role, resource, endpoint)
+			if err != nil {
+				http.Error(w, err.Error(), statusCode(err))
+				return
+			}
+
+			// request might be re-written by enforcer
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// statusCode maps an authorization error into HTTP status code
+func statusCode(err error) int {
+	switch err {
+	case nil:
+		return http.StatusOK
+	case ErrNoRole:
+		return http.StatusUnauthorized
+	case ErrResourceUnknown, ErrEndpointUnknown:
+		return http.StatusNotFound
+	default:
+		return http.StatusForbidden
+	}
+}
@@ -0,0 +1,122 @@
+package rbac_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bastianrob/go-experiences/rbac"
+)
+
+func TestMiddleware(t *testing.T) {
+	rbo := rbac.FromFile("./test.yaml")
+	extract := func(r *http.Request) (string, string, string) {
+		role, _ := r.Context().Value(rbac.ContextKeyRole).(string)
+		return role, "inquiry", "get"
+	}
+
+	tests := []struct {
+		given, when, then string
+		url               string
+		ctx               func() context.Context
+		wantStatus        int
+		queryResult       map[string]string
+	}{{
+		given: "Request without role",
+		when:  "accessing GET /inquiries", then: "responds 401",
+		url: "http://api.example.com/inquiries",
+		ctx: func() context.Context {
+			return context.Background()
+		},
+		wantStatus: http.StatusUnauthorized,
+	}, {
+		given: "Role is Client & email = client.one@email.com",
+		when:  "?created_by=client.other@email.com", then: "responds 403",
+		url: "http://api.example.com/inquiries?created_by=client.other@email.com",
+		ctx: func() context.Context {
+			ctx := context.WithValue(context.Background(), rbac.ContextKeyRole, "client")
+			return context.WithValue(ctx, rbac.ContextKey("email"), "client.one@email.com")
+		},
+		wantStatus: http.StatusForbidden,
+	}, {
+		given: "Role is Client & email = client.one@email.com",
+		when:  "?created_by=client.one@email.com", then: "responds 200",
+		url: "http://api.example.com/inquiries?created_by=client.one@email.com",
+		ctx: func() context.Context {
+			ctx := context.WithValue(context.Background(), rbac.ContextKeyRole, "client")
+			return context.WithValue(ctx, rbac.ContextKey("email"), "client.one@email.com")
+		},
+		wantStatus: http.StatusOK,
+	}, {
+		given: "Role is CS",
+		when:  "?status=Assigned", then: "responds 200 and downstream handler sees status=New",
+		url: "http://api.example.com/inquiries?status=Assigned",
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKeyRole, "cs")
+		},
+		wantStatus: http.StatusOK,
+		queryResult: map[string]string{
+			"status": "New",
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			var downstream *http.Request
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				downstream = r
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil).WithContext(tt.ctx())
+			rec := httptest.NewRecorder()
+			rbac.Middleware(rbo, extract)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code, "when: %s, then: %s", tt.when, tt.then)
+			if tt.wantStatus != http.StatusOK {
+				assert.Nil(t, downstream, "when: %s, then: %s", tt.when, tt.then)
+				return
+			}
+
+			for key, val := range tt.queryResult {
+				assert.Equal(t, val, downstream.URL.Query().Get(key), "when: %s, then: %s", tt.when, tt.then)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Unknown(t *testing.T) {
+	rbo := rbac.FromFile("./test.yaml")
+	tests := []struct {
+		given, then string
+		resource    string
+		endpoint    string
+		wantStatus  int
+	}{{
+		given: "Resource is unknown", then: "responds 404",
+		resource: "invoice", endpoint: "get",
+		wantStatus: http.StatusNotFound,
+	}, {
+		given: "Endpoint is unknown", then: "responds 404",
+		resource: "inquiry", endpoint: "archive",
+		wantStatus: http.StatusNotFound,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			extract := func(r *http.Request) (string, string, string) {
+				return "client", tt.resource, tt.endpoint
+			}
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "http://api.example.com/inquiries", nil)
+			rec := httptest.NewRecorder()
+			rbac.Middleware(rbo, extract)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code, tt.then)
+		})
+	}
+}
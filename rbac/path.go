@@ -0,0 +1,40 @@
+package rbac
+
+import (
+	"strings"
+)
+
+// PathParams extracts named segments from path based on a path template
+// The template is a slash separated list of segments, where:
+//   - a segment starting with ':' is a named parameter, which matches any single non-empty segment
+//     e.g: ':id' in '/inquiries/:id/assign' will capture 'INQ-0001' from '/inquiries/INQ-0001/assign'
+//   - any other segment is a literal, which must match the path segment exactly
+//
+// Leading and trailing slashes are ignored, and both template and path must have the same number of segments
+// Returns false if path does not match the template
+func PathParams(template, path string) (map[string]string, bool) {
+	tsegments := strings.Split(strings.Trim(template, "/"), "/")
+	psegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(tsegments) != len(psegments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, tsegment := range tsegments {
+		psegment := psegments[i]
+		if strings.HasPrefix(tsegment, ":") {
+			if psegment == "" {
+				return nil, false
+			}
+
+			params[tsegment[1:]] = psegment
+			continue
+		}
+
+		if tsegment != psegment {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
@@ -0,0 +1,51 @@
+package rbac_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bastianrob/go-experiences/rbac"
+)
+
+func TestPathParams(t *testing.T) {
+	tests := []struct {
+		given    string
+		then     string
+		template string
+		path     string
+		want     map[string]string
+		match    bool
+	}{{
+		given: "Template with a named segment", then: "named segment is extracted",
+		template: "/inquiries/:id/assign",
+		path:     "/inquiries/INQ-0001/assign",
+		want:     map[string]string{"id": "INQ-0001"},
+		match:    true,
+	}, {
+		given: "Template with multiple named segments and trailing slash", then: "all named segments are extracted",
+		template: "/tenants/:tenant/inquiries/:id",
+		path:     "/tenants/TNT-0001/inquiries/INQ-0001/",
+		want:     map[string]string{"tenant": "TNT-0001", "id": "INQ-0001"},
+		match:    true,
+	}, {
+		given: "Literal segment differs", then: "path does not match",
+		template: "/inquiries/:id/assign",
+		path:     "/inquiries/INQ-0001/close",
+	}, {
+		given: "Path have more segments than template", then: "path does not match",
+		template: "/inquiries/:id",
+		path:     "/inquiries/INQ-0001/assign",
+	}, {
+		given: "Named segment is empty", then: "path does not match",
+		template: "/inquiries/:id/assign",
+		path:     "/inquiries//assign",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			got, match := rbac.PathParams(tt.template, tt.path)
+			assert.Equal(t, tt.match, match, tt.then)
+			assert.Equal(t, tt.want, got, tt.then)
+		})
+	}
+}
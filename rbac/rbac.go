@@ -1,16 +1,26 @@
 package rbac
 
 import (
+	"encoding/json"
+	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 
 	yaml "gopkg.in/yaml.v2"
 )
 
+// Wildcard matches any resource or endpoint which has no exact entry
+const Wildcard = "*"
+
 // Permission of a role to an endpoint
 type Permission struct {
-	Allow   bool     `yaml:"allow"`
-	Ensure  Ensurer  `yaml:"ensure,omitempty"`
-	Enforce Enforcer `yaml:"enforce,omitempty"`
+	Allow       bool         `yaml:"allow" json:"allow"`
+	ActiveHours *ActiveHours `yaml:"active_hours,omitempty" json:"active_hours,omitempty"`
+	Deny        []Rule       `yaml:"deny,omitempty" json:"deny,omitempty"`
+	Ensure      Ensurer      `yaml:"ensure,omitempty" json:"ensure,omitempty"`
+	Enforce     Enforcer     `yaml:"enforce,omitempty" json:"enforce,omitempty"`
 }
 
 // Endpoint is a map of {endpoint: permission}
@@ -22,18 +32,89 @@ type Resource map[string]Endpoint
 // RBAC is a map of {role: resource}
 type RBAC map[string]Resource
 
+// lookup permission of a resource and endpoint, exact entries are preferred over wildcard
+// in the order of: {resource, endpoint}, {resource, *}, {*, endpoint}, {*, *}
+func (resources Resource) lookup(resource, endpoint string) (Permission, error) {
+	err := ErrResourceUnknown
+	for _, res := range [2]string{resource, Wildcard} {
+		endpoints, exists := resources[res]
+		if !exists {
+			continue
+		}
+
+		err = ErrEndpointUnknown
+		for _, ep := range [2]string{endpoint, Wildcard} {
+			if permission, exists := endpoints[ep]; exists {
+				return permission, nil
+			}
+		}
+	}
+
+	return Permission{}, err
+}
+
 // FromFile creates a new RBAC object from .yaml file
 func FromFile(path string) *RBAC {
-	f, err := ioutil.ReadFile(path)
+	f, err := os.Open(path)
 	if err != nil {
 		return nil
 	}
+	defer f.Close()
 
-	rbac := &RBAC{}
-	err = yaml.Unmarshal(f, rbac)
+	rbac, err := FromReader(f)
 	if err != nil {
 		return nil
 	}
 
 	return rbac
 }
+
+// FromReader creates a new RBAC object from yaml read from r
+func FromReader(r io.Reader) (*RBAC, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromBytes(b)
+}
+
+// FromBytes creates a new RBAC object from yaml bytes
+func FromBytes(b []byte) (*RBAC, error) {
+	rbac := &RBAC{}
+	err := yaml.Unmarshal(b, rbac)
+	if err != nil {
+		return nil, err
+	}
+
+	return rbac, nil
+}
+
+// FromJSON creates a new RBAC object from json bytes
+func FromJSON(b []byte) (*RBAC, error) {
+	rbac := &RBAC{}
+	err := json.Unmarshal(b, rbac)
+	if err != nil {
+		return nil, err
+	}
+
+	return rbac, nil
+}
+
+// Load creates a new RBAC object from either .yaml, .yml, or .json file
+// The format is picked based on file extension
+func Load(path string) (*RBAC, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FromBytes(b)
+	case ".json":
+		return FromJSON(b)
+	}
+
+	return nil, ErrUnknownFormat
+}
@@ -0,0 +1,84 @@
+package rbac_test
+
+import (
+	_ "embed"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bastianrob/go-experiences/rbac"
+)
+
+//go:embed test.yaml
+var testYAML []byte
+
+func TestFromReader(t *testing.T) {
+	tests := []struct {
+		given   string
+		then    string
+		source  string
+		wantErr bool
+	}{{
+		given: "Embedded test.yaml", then: "must be equal to RBAC loaded from file",
+		source: string(testYAML),
+	}, {
+		given: "Malformed yaml", then: "must return error",
+		source:  "client: [inquiry",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			got, err := rbac.FromReader(strings.NewReader(tt.source))
+			if tt.wantErr {
+				assert.Error(t, err, tt.then)
+				assert.Nil(t, got, tt.then)
+			} else {
+				assert.NoError(t, err, tt.then)
+				assert.Equal(t, rbac.FromFile("./test.yaml"), got, tt.then)
+			}
+		})
+	}
+}
+
+func TestFromBytes(t *testing.T) {
+	got, err := rbac.FromBytes(testYAML)
+	assert.NoError(t, err)
+	assert.Equal(t, rbac.FromFile("./test.yaml"), got)
+	assert.True(t, (*got)["client"]["inquiry"]["get"].Allow)
+	assert.False(t, (*got)["client"]["inquiry"]["assign"].Allow)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		given   string
+		then    string
+		path    string
+		wantErr bool
+	}{{
+		given: "A .yaml file", then: "must be loaded as yaml",
+		path: "./test.yaml",
+	}, {
+		given: "A .json file", then: "must be loaded as json, equal to its yaml counterpart",
+		path: "./test.json",
+	}, {
+		given: "A file with unknown extension", then: "must return error",
+		path:    "./readme.md",
+		wantErr: true,
+	}, {
+		given: "A file which does not exists", then: "must return error",
+		path:    "./not-exists.json",
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			got, err := rbac.Load(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err, tt.then)
+			} else {
+				assert.NoError(t, err, tt.then)
+				assert.Equal(t, rbac.FromFile("./test.yaml"), got, tt.then)
+			}
+		})
+	}
+}
@@ -2,22 +2,62 @@ package rbac
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
+// Multi value modes of a rule
+const (
+	MultiAll = "all" // all values must comply, the default
+	MultiAny = "any" // at least one value must comply
+)
+
+// Compare modes of a rule
+const (
+	CompareStrict = "strict" // values must be deeply equal, the default
+	CompareCI     = "ci"     // strings are compared case-insensitively
+	CompareCoerce = "coerce" // values are compared in their string form, or numerically when both are numbers
+)
+
 // Rule of a permission
 type Rule struct {
-	Key      string `yaml:"key"`
-	Operator string `yaml:"operator"`
-	Value    string `yaml:"value"`
+	Key      string `yaml:"key" json:"key"`
+	Operator string `yaml:"operator" json:"operator,omitempty"`
+	Value    string `yaml:"value" json:"value"`
+
+	// Append tells enforcer to add the value alongside existing values instead of overwriting them
+	Append bool `yaml:"append,omitempty" json:"append,omitempty"`
+
+	// Multi tells ensurer to evaluate all values of a repeated key, e.g: ?status=New&status=Assigned
+	// instead of only the first one, and MultiMode is either MultiAll or MultiAny
+	Multi     bool   `yaml:"multi,omitempty" json:"multi,omitempty"`
+	MultiMode string `yaml:"multiMode,omitempty" json:"multiMode,omitempty"`
+
+	// CompareMode tells how expected and actual values are compared, either CompareStrict, CompareCI, or CompareCoerce
+	// e.g: coerce lets a query value "1" complies with an int 1 stored in ctx
+	CompareMode string `yaml:"compareMode,omitempty" json:"compareMode,omitempty"`
 }
 
 // FromContext get actual rule.Value from ctx if rule.Value starts with ctx
 // otherwise, return rule.Value as is
+// Panics if rule.Value is not a valid path in ctx, use FromContextSafe to get an error instead
 func (rule Rule) FromContext(ctx context.Context) interface{} {
+	ctxval, err := rule.FromContextSafe(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	return ctxval
+}
+
+// FromContextSafe get actual rule.Value from ctx if rule.Value starts with ctx
+// otherwise, return rule.Value as is
+// Returns ErrInvalidContextPath if rule.Value is not a valid path in ctx
+func (rule Rule) FromContextSafe(ctx context.Context) (interface{}, error) {
 	if !strings.HasPrefix(rule.Value, "ctx") {
-		return rule.Value
+		return rule.Value, nil
 	}
 
 	paths := strings.Split(rule.Value, ".")
@@ -30,30 +70,131 @@ func (rule Rule) FromContext(ctx context.Context) interface{} {
 		//Get current context index
 		if i == 1 {
 			ctxval = ctx.Value(ContextKey(ctxkey))
-		} else {
+		} else if kvp, ok := ctxval.(map[string]interface{}); ok {
 			// if rule.Value is nested more than 1 level, we assume the context value is of type map[string]interface{}
-			// otherwise, panic
-			var ok bool
-			kvp := ctxval.(map[string]interface{})
 			ctxval, ok = kvp[ctxkey]
 			if !ok || ctxval == nil {
 				ctxval = nil
 			}
+		} else if arr := reflect.ValueOf(ctxval); arr.Kind() == reflect.Slice || arr.Kind() == reflect.Array {
+			// or a slice / array, in which case the path must be a valid index
+			idx, err := strconv.Atoi(ctxkey)
+			if err != nil || idx < 0 || idx >= arr.Len() {
+				return nil, fmt.Errorf("%w: '%s' is not a valid index at '%s'",
+					ErrInvalidContextPath, rule.Value, strings.Join(paths[:i], "."))
+			}
+
+			ctxval = arr.Index(idx).Interface()
+		} else {
+			// otherwise, rule.Value is not a valid path
+			return nil, fmt.Errorf("%w: '%s' is not a map or slice at '%s'",
+				ErrInvalidContextPath, rule.Value, strings.Join(paths[:i], "."))
 		}
 	}
 
-	return ctxval
+	return ctxval, nil
 }
 
 // Comply checks does request value complies with our rule
 func (rule Rule) Comply(expected, actual interface{}) bool {
 	switch rule.Operator {
 	case "!=":
-		return !reflect.DeepEqual(expected, actual)
+		return !rule.equal(expected, actual)
 	case "=":
-		return reflect.DeepEqual(expected, actual)
+		return rule.equal(expected, actual)
+	case "contains":
+		// only a string can contains another string
+		expectedStr, actualStr, ok := rule.asStrings(expected, actual)
+		if !ok {
+			return false
+		}
+
+		return strings.Contains(actualStr, expectedStr)
+	case "absent":
+		// expected value is ignored, actual must be missing or empty, e.g: a privileged query param
+		return actual == nil || actual == ""
 	}
 
 	// doesn't comply if we don't recognize the rule operator
 	return false
 }
+
+// equal checks whether expected and actual values are equal, based on CompareMode
+// Values which can't be compared in the given mode falls back to strict comparison
+func (rule Rule) equal(expected, actual interface{}) bool {
+	switch rule.CompareMode {
+	case CompareCI:
+		expectedStr, isString := expected.(string)
+		actualStr, isAlsoString := actual.(string)
+		if isString && isAlsoString {
+			return strings.EqualFold(expectedStr, actualStr)
+		}
+	case CompareCoerce:
+		expectedStr, actualStr, ok := rule.asStrings(expected, actual)
+		if !ok {
+			break
+		}
+		if expectedStr == actualStr {
+			return true
+		}
+
+		// e.g: "0001" equals to 1
+		expectedNum, err := strconv.ParseFloat(expectedStr, 64)
+		if err != nil {
+			return false
+		}
+		actualNum, err := strconv.ParseFloat(actualStr, 64)
+		return err == nil && expectedNum == actualNum
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
+
+// asStrings get expected and actual values as strings, based on CompareMode
+// Only coerce mode converts non string values, and ci mode lower cases both strings
+func (rule Rule) asStrings(expected, actual interface{}) (expectedStr, actualStr string, ok bool) {
+	if rule.CompareMode == CompareCoerce {
+		var err error
+		if expectedStr, err = stringify(expected); err != nil {
+			return "", "", false
+		}
+		if actualStr, err = stringify(actual); err != nil {
+			return "", "", false
+		}
+
+		return expectedStr, actualStr, true
+	}
+
+	expectedStr, isString := expected.(string)
+	if !isString {
+		return "", "", false
+	}
+	actualStr, isString = actual.(string)
+	if !isString {
+		return "", "", false
+	}
+
+	if rule.CompareMode == CompareCI {
+		return strings.ToLower(expectedStr), strings.ToLower(actualStr), true
+	}
+
+	return expectedStr, actualStr, true
+}
+
+// ComplyValues checks does all request values of a repeated key complies with our rule, based on MultiMode
+// A key without value is evaluated as a single empty value
+func (rule Rule) ComplyValues(expected interface{}, actuals []string) bool {
+	if len(actuals) <= 0 {
+		actuals = []string{""}
+	}
+
+	// in any mode, the first compliant value decides, otherwise the first violating value decides
+	anyMode := rule.MultiMode == MultiAny
+	for _, actual := range actuals {
+		if rule.Comply(expected, actual) == anyMode {
+			return anyMode
+		}
+	}
+
+	return !anyMode
+}
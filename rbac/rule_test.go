@@ -2,6 +2,7 @@ package rbac_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/bastianrob/go-experiences/rbac"
@@ -37,6 +38,15 @@ func TestRule_FromContext(t *testing.T) {
 			})
 		},
 		want: "IDX-0001",
+	}, {
+		given: "rule.Value with deep nested ctx, indexing a slice", then: "return value should be the indexed element",
+		rule: rbac.Rule{Value: "ctx.access.roles.0"},
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("access"), map[string]interface{}{
+				"roles": []interface{}{"client", "cs"},
+			})
+		},
+		want: "client",
 	}, {
 		given: "rule.Value with deep nested ctx, but at 4th level its not a map", then: "code should panic",
 		rule: rbac.Rule{Value: "ctx.access.id.name"},
@@ -68,6 +78,96 @@ func TestRule_FromContext(t *testing.T) {
 	}
 }
 
+func TestRule_FromContextSafe(t *testing.T) {
+	tests := []struct {
+		given   string
+		then    string
+		rule    rbac.Rule
+		ctx     func() context.Context
+		want    interface{}
+		wantErr bool
+	}{{
+		given: "Non ctx rule.Value", then: "return value should be rule.Value as is",
+		rule: rbac.Rule{Value: "something"},
+		ctx:  func() context.Context { return context.Background() },
+		want: "something",
+	}, {
+		given: "rule.Value with deep nested ctx", then: "return value should be taken from ctx",
+		rule: rbac.Rule{Value: "ctx.access.id"},
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("access"), map[string]interface{}{
+				"id": "IDX-0001",
+			})
+		},
+		want: "IDX-0001",
+	}, {
+		given: "rule.Value with deep nested ctx, indexing a slice", then: "return value should be the indexed element",
+		rule: rbac.Rule{Value: "ctx.access.roles.0"},
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("access"), map[string]interface{}{
+				"roles": []string{"client", "cs"},
+			})
+		},
+		want: "client",
+	}, {
+		given: "rule.Value with deep nested ctx, indexing a slice of maps", then: "return value should be taken from the indexed map",
+		rule: rbac.Rule{Value: "ctx.tenants.1.id"},
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("tenants"), []interface{}{
+				map[string]interface{}{"id": "TNT-0001"},
+				map[string]interface{}{"id": "TNT-0002"},
+			})
+		},
+		want: "TNT-0002",
+	}, {
+		given: "rule.Value with deep nested ctx, index out of range", then: "should return error",
+		rule: rbac.Rule{Value: "ctx.access.roles.2"},
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("access"), map[string]interface{}{
+				"roles": []string{"client", "cs"},
+			})
+		},
+		wantErr: true,
+	}, {
+		given: "rule.Value with deep nested ctx, index is not a number", then: "should return error",
+		rule: rbac.Rule{Value: "ctx.access.roles.first"},
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("access"), map[string]interface{}{
+				"roles": []string{"client", "cs"},
+			})
+		},
+		wantErr: true,
+	}, {
+		given: "rule.Value with deep nested ctx, but at 4th level its not a map", then: "should return error",
+		rule: rbac.Rule{Value: "ctx.access.id.name"},
+		ctx: func() context.Context {
+			return context.WithValue(context.Background(), rbac.ContextKey("access"), map[string]interface{}{
+				"id": "IDX-0001",
+			})
+		},
+		wantErr: true,
+	}, {
+		given: "rule.Value with deep nested ctx, but does not exists", then: "should return error",
+		rule: rbac.Rule{Value: "ctx.something.not.exists"},
+		ctx: func() context.Context {
+			return context.Background()
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			got, err := tt.rule.FromContextSafe(tt.ctx())
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, rbac.ErrInvalidContextPath), tt.then)
+				assert.Nil(t, got, tt.then)
+			} else {
+				assert.NoError(t, err, tt.then)
+				assert.Equal(t, tt.want, got, tt.then)
+			}
+		})
+	}
+}
+
 func TestRule_Comply(t *testing.T) {
 	type args struct {
 		expected interface{}
@@ -99,6 +199,74 @@ func TestRule_Comply(t *testing.T) {
 			actual:   "another",
 		},
 		want: true,
+	}, {
+		given: "With rule: actual must be = expected, but it's not", then: "query does not complies",
+		rule: rbac.Rule{
+			Operator: "=",
+		},
+		args: args{
+			expected: "something",
+			actual:   "another",
+		},
+		want: false,
+	}, {
+		given: "With rule: actual must contains expected", then: "query complies with our rule",
+		rule: rbac.Rule{
+			Operator: "contains",
+		},
+		args: args{
+			expected: "TNT-0001",
+			actual:   "/tenants/TNT-0001/inquiries",
+		},
+		want: true,
+	}, {
+		given: "With rule: actual must contains expected, but it's absent", then: "query does not complies",
+		rule: rbac.Rule{
+			Operator: "contains",
+		},
+		args: args{
+			expected: "TNT-0001",
+			actual:   "/tenants/TNT-0002/inquiries",
+		},
+		want: false,
+	}, {
+		given: "With rule: actual must contains expected, but expected is not a string", then: "query does not complies",
+		rule: rbac.Rule{
+			Operator: "contains",
+		},
+		args: args{
+			expected: 1,
+			actual:   "/tenants/1/inquiries",
+		},
+		want: false,
+	}, {
+		given: "With rule: actual must contains expected, but actual is not a string", then: "query does not complies",
+		rule: rbac.Rule{
+			Operator: "contains",
+		},
+		args: args{
+			expected: "TNT-0001",
+			actual:   []string{"TNT-0001"},
+		},
+		want: false,
+	}, {
+		given: "With rule: actual must be absent", then: "query complies with our rule",
+		rule: rbac.Rule{
+			Operator: "absent",
+		},
+		args: args{
+			actual: "",
+		},
+		want: true,
+	}, {
+		given: "With rule: actual must be absent, but it's present", then: "query does not complies",
+		rule: rbac.Rule{
+			Operator: "absent",
+		},
+		args: args{
+			actual: "some notes",
+		},
+		want: false,
 	}, {
 		given: "With rule operator not known", then: "query does not complies",
 		rule: rbac.Rule{
@@ -113,3 +281,52 @@ func TestRule_Comply(t *testing.T) {
 		})
 	}
 }
+
+func TestRule_Comply_CompareMode(t *testing.T) {
+	tests := []struct {
+		given, then string
+		mode        string
+		operator    string
+		expected    interface{}
+		actual      interface{}
+		want        bool
+	}{
+		// strict
+		{given: "Strict mode, int in ctx and its string form in query", then: "does not comply",
+			mode: rbac.CompareStrict, operator: "=", expected: 1, actual: "1", want: false},
+		{given: "Strict mode, different case", then: "does not comply",
+			mode: "", operator: "=", expected: "New", actual: "new", want: false},
+		{given: "Strict mode, same string", then: "complies",
+			mode: rbac.CompareStrict, operator: "=", expected: "New", actual: "New", want: true},
+		// case insensitive
+		{given: "CI mode, different case", then: "complies",
+			mode: rbac.CompareCI, operator: "=", expected: "New", actual: "NEW", want: true},
+		{given: "CI mode, different case with != operator", then: "does not comply",
+			mode: rbac.CompareCI, operator: "!=", expected: "New", actual: "new", want: false},
+		{given: "CI mode, contains in different case", then: "complies",
+			mode: rbac.CompareCI, operator: "contains", expected: "tnt-0001", actual: "/tenants/TNT-0001", want: true},
+		{given: "CI mode, int in ctx and its string form in query", then: "does not comply",
+			mode: rbac.CompareCI, operator: "=", expected: 1, actual: "1", want: false},
+		// coerce
+		{given: "Coerce mode, int in ctx and its string form in query", then: "complies",
+			mode: rbac.CompareCoerce, operator: "=", expected: 1, actual: "1", want: true},
+		{given: "Coerce mode, padded number in query", then: "complies numerically",
+			mode: rbac.CompareCoerce, operator: "=", expected: 1, actual: "0001", want: true},
+		{given: "Coerce mode, different number", then: "does not comply",
+			mode: rbac.CompareCoerce, operator: "=", expected: 1, actual: "2", want: false},
+		{given: "Coerce mode, bool in ctx", then: "complies",
+			mode: rbac.CompareCoerce, operator: "=", expected: true, actual: "true", want: true},
+		{given: "Coerce mode, different case", then: "does not comply",
+			mode: rbac.CompareCoerce, operator: "=", expected: "New", actual: "new", want: false},
+		{given: "Coerce mode, contains an int", then: "complies",
+			mode: rbac.CompareCoerce, operator: "contains", expected: 1, actual: "/tenants/1", want: true},
+		{given: "Coerce mode, value which can't be coerced", then: "falls back to strict",
+			mode: rbac.CompareCoerce, operator: "=", expected: []string{"1"}, actual: "1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.given, func(t *testing.T) {
+			rule := rbac.Rule{Operator: tt.operator, CompareMode: tt.mode}
+			assert.Equal(t, tt.want, rule.Comply(tt.expected, tt.actual), tt.then)
+		})
+	}
+}
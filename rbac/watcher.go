@@ -0,0 +1,124 @@
+package rbac
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultWatchInterval is how often WatchFile polls the config file for changes
+const DefaultWatchInterval = 5 * time.Second
+
+// Watcher holds an RBAC loaded from file, and reloads it whenever the file changes
+// Every call to Authorize sees a consistent snapshot of the RBAC
+type Watcher struct {
+	path     string
+	interval time.Duration
+	current  atomic.Value // RBAC
+
+	// last known file state
+	modtime time.Time
+	size    int64
+
+	// exit mechanism
+	once sync.Once
+	stop chan struct{}
+	done chan struct{}
+}
+
+// WatchFile loads RBAC from a .yaml, .yml, or .json file and polls the file for changes
+// Returns the watcher and a function to stop watching
+func WatchFile(path string) (*Watcher, func() error, error) {
+	return WatchFileEvery(path, DefaultWatchInterval)
+}
+
+// WatchFileEvery is just like WatchFile, but polls the file every given interval
+func WatchFileEvery(path string, interval time.Duration) (*Watcher, func() error, error) {
+	watcher := &Watcher{
+		path:     path,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rbac, err := Load(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	watcher.current.Store(*rbac)
+	watcher.modtime = info.ModTime()
+	watcher.size = info.Size()
+
+	go watcher.watch()
+	return watcher, watcher.close, nil
+}
+
+// RBAC returns the current snapshot of RBAC
+func (watcher *Watcher) RBAC() RBAC {
+	return watcher.current.Load().(RBAC)
+}
+
+// Authorize a request based on its role, resource, and endpoint, using the current snapshot of RBAC
+func (watcher *Watcher) Authorize(r *http.Request, role, resource, endpoint string) error {
+	return watcher.RBAC().Authorize(r, role, resource, endpoint)
+}
+
+func (watcher *Watcher) watch() {
+	defer close(watcher.done)
+
+	ticker := time.NewTicker(watcher.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			watcher.reload()
+		case <-watcher.stop:
+			return
+		}
+	}
+}
+
+// reload swaps the current RBAC if file have changed
+// a malformed file is logged, and the previous RBAC is retained
+func (watcher *Watcher) reload() {
+	info, err := os.Stat(watcher.path)
+	if err != nil {
+		log.Println("rbac: failed to watch", watcher.path, "err:", err)
+		return
+	}
+
+	if info.ModTime().Equal(watcher.modtime) && info.Size() == watcher.size {
+		return
+	}
+
+	// remember the file state even if it's malformed, so we don't log the same failure on every tick
+	watcher.modtime = info.ModTime()
+	watcher.size = info.Size()
+
+	rbac, err := Load(watcher.path)
+	if err != nil {
+		log.Println("rbac: failed to reload", watcher.path, "err:", err)
+		return
+	}
+
+	watcher.current.Store(*rbac)
+}
+
+// close stops the watcher from polling the file
+func (watcher *Watcher) close() error {
+	watcher.once.Do(func() {
+		close(watcher.stop)
+	})
+
+	<-watcher.done
+	return nil
+}
@@ -0,0 +1,63 @@
+package rbac_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bastianrob/go-experiences/rbac"
+)
+
+func TestWatchFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rbac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rbac.yaml")
+	write := func(content string) {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	authorize := func(watcher *rbac.Watcher) error {
+		req, _ := http.NewRequest("POST", "http://api.example.com/inquiries", nil)
+		return watcher.Authorize(req, "client", "inquiry", "create")
+	}
+
+	write("client:\n  inquiry:\n    create:\n      allow: false\n")
+	watcher, stop, err := rbac.WatchFileEvery(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	assert.Equal(t, rbac.ErrForbidden, authorize(watcher), "initially client is not allowed to create")
+
+	// policy changes without redeploy
+	write("client:\n  inquiry:\n    create:\n      allow: true\n")
+	assert.Eventually(t, func() bool {
+		return authorize(watcher) == nil
+	}, time.Second, 10*time.Millisecond, "client must be allowed to create after reload")
+
+	// malformed policy must not replace the previous one
+	write("client: [inquiry")
+	time.Sleep(50 * time.Millisecond)
+	assert.NoError(t, authorize(watcher), "previous config must be retained after a malformed reload")
+
+	assert.NoError(t, stop())
+	assert.NoError(t, stop(), "stop must be safe to call more than once")
+}
+
+func TestWatchFile_NotExists(t *testing.T) {
+	watcher, stop, err := rbac.WatchFile("./not-exists.yaml")
+	assert.Error(t, err)
+	assert.Nil(t, watcher)
+	assert.Nil(t, stop)
+}
@@ -0,0 +1,12 @@
+package reduce
+
+// ReduceG an array of T into A, type checked at compile time
+// Unlike Reduce, it does not use reflection, and is the recommended way when the types are known
+func ReduceG[T, A any](source []T, initial A, reducer func(acc A, entry T, idx int) A) A {
+	acc := initial
+	for i, entry := range source {
+		acc = reducer(acc, entry, i)
+	}
+
+	return acc
+}
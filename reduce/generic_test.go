@@ -0,0 +1,65 @@
+package reduce
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReduceG(t *testing.T) {
+	type Person struct {
+		Name       string
+		Birthplace string
+	}
+	type PersonGroup map[string][]string
+	type SumAvg struct {
+		Sum int
+		Avg float32
+	}
+
+	t.Run("Sum of array", func(t *testing.T) {
+		got := ReduceG([]int{1, 2, 3}, 0, func(accumulator, entry, idx int) int {
+			return accumulator + entry
+		})
+		if got != 6 {
+			t.Errorf("ReduceG() = %v, want %v", got, 6)
+		}
+	})
+
+	t.Run("Avg of array", func(t *testing.T) {
+		got := ReduceG([]int{1, 2, 3}, SumAvg{}, func(accumulator SumAvg, entry, idx int) SumAvg {
+			sum := accumulator.Sum + entry
+			return SumAvg{
+				Sum: sum,
+				Avg: float32(sum) / float32(idx+1),
+			}
+		})
+		if want := (SumAvg{Sum: 6, Avg: 6 / 3}); got != want {
+			t.Errorf("ReduceG() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Group by person's name", func(t *testing.T) {
+		source := []Person{
+			{"John Doe", "Jakarta"},
+			{"John Doe", "Depok"},
+			{"John Doe", "Medan"},
+		}
+		got := ReduceG(source, make(PersonGroup), func(accumulator PersonGroup, entry Person, idx int) PersonGroup {
+			accumulator[entry.Name] = append(accumulator[entry.Name], entry.Birthplace)
+			return accumulator
+		})
+		want := PersonGroup{"John Doe": []string{"Jakarta", "Depok", "Medan"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReduceG() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Empty array", func(t *testing.T) {
+		got := ReduceG(nil, 42, func(accumulator, entry, idx int) int {
+			return accumulator + entry
+		})
+		if got != 42 {
+			t.Errorf("ReduceG() = %v, want %v", got, 42)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package reduce
+
+import (
+	"reflect"
+)
+
+// GroupBy an array of T into map[K][]T, where K is the key of each entry
+// keyFunc is a func(T) K, where K must be comparable
+func GroupBy(source, keyFunc interface{}) (interface{}, error) {
+	srcV := reflect.ValueOf(source)
+	kv, err := validateKeyFunc(srcV, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	T := srcV.Type().Elem() // 1. Get type T of source's element
+	K := kv.Type().Out(0)   // 2. Get type K of key function's result
+	sliceOfT := reflect.SliceOf(T)
+	groups := reflect.MakeMap(reflect.MapOf(K, sliceOfT)) // 3. var groups = map[K][]T{}
+
+	for i := 0; i < srcV.Len(); i++ {
+		entry := srcV.Index(i)
+		key := kv.Call([]reflect.Value{entry})[0]
+
+		group := groups.MapIndex(key)
+		if !group.IsValid() {
+			group = reflect.MakeSlice(sliceOfT, 0, 1)
+		}
+		groups.SetMapIndex(key, reflect.Append(group, entry))
+	}
+
+	return groups.Interface(), nil
+}
+
+// ToMap an array of T into map[K]T, where K is the key of each entry
+// keyFunc is a func(T) K, where K must be comparable. On duplicate keys, the last entry wins
+func ToMap(source, keyFunc interface{}) (interface{}, error) {
+	srcV := reflect.ValueOf(source)
+	kv, err := validateKeyFunc(srcV, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	T := srcV.Type().Elem()                        // 1. Get type T of source's element
+	K := kv.Type().Out(0)                          // 2. Get type K of key function's result
+	lookup := reflect.MakeMap(reflect.MapOf(K, T)) // 3. var lookup = map[K]T{}
+
+	for i := 0; i < srcV.Len(); i++ {
+		entry := srcV.Index(i)
+		key := kv.Call([]reflect.Value{entry})[0]
+		lookup.SetMapIndex(key, entry)
+	}
+
+	return lookup.Interface(), nil
+}
+
+// validateKeyFunc check that source is an array of T, and keyFunc is a func(T) K where K is comparable
+func validateKeyFunc(srcV reflect.Value, keyFunc interface{}) (reflect.Value, error) {
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return reflect.Value{}, ErrSourceNotArray
+	}
+
+	if keyFunc == nil {
+		return reflect.Value{}, ErrKeyFuncNil
+	}
+
+	T := srcV.Type().Elem()
+	kv := reflect.ValueOf(keyFunc)
+	if kv.Kind() != reflect.Func || kv.Type().NumIn() != 1 || !T.AssignableTo(kv.Type().In(0)) ||
+		kv.Type().NumOut() != 1 || !kv.Type().Out(0).Comparable() {
+		return reflect.Value{}, ErrKeyFuncNotFunc
+	}
+
+	return kv, nil
+}
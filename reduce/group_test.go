@@ -0,0 +1,198 @@
+package reduce
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupBy(t *testing.T) {
+	type Person struct {
+		Name       string
+		Birthplace string
+	}
+
+	type args struct {
+		source  interface{}
+		keyFunc interface{}
+	}
+
+	nameOf := func(entry Person) string {
+		return entry.Name
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Source must be an array",
+			args:    args{source: "something", keyFunc: nameOf},
+			wantErr: true,
+		},
+		{
+			name:    "Key function must not be nil",
+			args:    args{source: []Person{}, keyFunc: nil},
+			wantErr: true,
+		},
+		{
+			name:    "Key function must be a function",
+			args:    args{source: []Person{}, keyFunc: "something"},
+			wantErr: true,
+		},
+		{
+			name: "Key must be comparable",
+			args: args{source: []Person{}, keyFunc: func(entry Person) []string {
+				return []string{entry.Name}
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "Key function must take exactly one argument",
+			args:    args{source: []Person{}, keyFunc: func() string { return "" }},
+			wantErr: true,
+		},
+		{
+			name:    "Key function argument must accept source's element",
+			args:    args{source: []Person{{"John Doe", "Jakarta"}}, keyFunc: func(entry int) string { return "" }},
+			wantErr: true,
+		},
+		{
+			name:    "Group of empty array",
+			args:    args{source: []Person{}, keyFunc: nameOf},
+			wantErr: false,
+			want:    map[string][]Person{},
+		},
+		{
+			name: "Group by person's name",
+			args: args{
+				source: []Person{
+					Person{"John Doe", "Jakarta"},
+					Person{"John Doe", "Depok"},
+					Person{"Jane Doe", "Bandung"},
+					Person{"John Doe", "Medan"},
+				},
+				keyFunc: nameOf,
+			},
+			wantErr: false,
+			want: map[string][]Person{
+				"John Doe": []Person{{"John Doe", "Jakarta"}, {"John Doe", "Depok"}, {"John Doe", "Medan"}},
+				"Jane Doe": []Person{{"Jane Doe", "Bandung"}},
+			},
+		},
+		{
+			name: "Group by odd or even",
+			args: args{
+				source: [5]int{1, 2, 3, 4, 5},
+				keyFunc: func(entry int) bool {
+					return entry%2 == 0
+				},
+			},
+			wantErr: false,
+			want:    map[bool][]int{false: {1, 3, 5}, true: {2, 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GroupBy(tt.args.source, tt.args.keyFunc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GroupBy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToMap(t *testing.T) {
+	type Person struct {
+		ID   int
+		Name string
+	}
+
+	type args struct {
+		source  interface{}
+		keyFunc interface{}
+	}
+
+	idOf := func(entry Person) int {
+		return entry.ID
+	}
+	nameOf := func(entry Person) string {
+		return entry.Name
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name:    "Source must be an array",
+			args:    args{source: "something", keyFunc: idOf},
+			wantErr: true,
+		},
+		{
+			name:    "Key function must not be nil",
+			args:    args{source: []Person{}, keyFunc: nil},
+			wantErr: true,
+		},
+		{
+			name: "Key must be comparable",
+			args: args{source: []Person{}, keyFunc: func(entry Person) []int {
+				return []int{entry.ID}
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "Key function must take exactly one argument",
+			args:    args{source: []Person{}, keyFunc: func(a, b Person) int { return a.ID }},
+			wantErr: true,
+		},
+		{
+			name:    "Key function argument must accept source's element",
+			args:    args{source: []Person{{1, "John Doe"}}, keyFunc: func(entry string) int { return 0 }},
+			wantErr: true,
+		},
+		{
+			name:    "Map of empty array",
+			args:    args{source: []Person{}, keyFunc: idOf},
+			wantErr: false,
+			want:    map[int]Person{},
+		},
+		{
+			name: "Map by person's id",
+			args: args{
+				source:  []Person{{1, "John Doe"}, {2, "Jane Doe"}},
+				keyFunc: idOf,
+			},
+			wantErr: false,
+			want:    map[int]Person{1: {1, "John Doe"}, 2: {2, "Jane Doe"}},
+		},
+		{
+			name: "Map by person's name, last one wins",
+			args: args{
+				source:  [3]Person{{1, "John Doe"}, {2, "Jane Doe"}, {3, "John Doe"}},
+				keyFunc: nameOf,
+			},
+			wantErr: false,
+			want:    map[string]Person{"John Doe": {3, "John Doe"}, "Jane Doe": {2, "Jane Doe"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToMap(tt.args.source, tt.args.keyFunc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToMap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,6 +10,9 @@ var (
 	ErrSourceNotArray = errors.New("Source value is not an array")
 	ErrReducerNil     = errors.New("Reducer function cannot be nil")
 	ErrReducerNotFunc = errors.New("Reducer argument must be a function")
+	ErrKeyFuncNil     = errors.New("Key function cannot be nil")
+	ErrKeyFuncNotFunc = errors.New("Key function argument must be a function returning a comparable key")
+	ErrReducerNotStop = errors.New("Reducer function must return an accumulator and a stop flag")
 )
 
 //Reduce an array of something into another thing
@@ -47,3 +50,78 @@ func Reduce(source, initialValue, reducer interface{}) (interface{}, error) {
 
 	return accV.Interface(), nil
 }
+
+// ReduceUntil an array of something into another thing, stopping as soon as the answer is known
+// reducer is a func(accumulator, entry, idx) (accumulator, bool), and returning true stops the iteration
+func ReduceUntil(source, initialValue, reducer interface{}) (interface{}, error) {
+	srcV := reflect.ValueOf(source)
+	kind := srcV.Kind()
+	if kind != reflect.Slice && kind != reflect.Array {
+		return nil, ErrSourceNotArray
+	}
+
+	if reducer == nil {
+		return nil, ErrReducerNil
+	}
+
+	rv := reflect.ValueOf(reducer)
+	if rv.Kind() != reflect.Func {
+		return nil, ErrReducerNotFunc
+	}
+
+	if rv.Type().NumOut() != 2 || rv.Type().Out(1).Kind() != reflect.Bool {
+		return nil, ErrReducerNotStop
+	}
+
+	accV := reflect.ValueOf(initialValue)
+	for i := 0; i < srcV.Len(); i++ {
+		// call reducer via reflection
+		reduceResults := rv.Call([]reflect.Value{
+			accV,               // send accumulator value
+			srcV.Index(i),      // send current source entry
+			reflect.ValueOf(i), // send current loop index
+		})
+
+		accV = reduceResults[0]
+		if reduceResults[1].Bool() {
+			break
+		}
+	}
+
+	return accV.Interface(), nil
+}
+
+// ReduceChan reduce entries received from a channel into another thing, until the channel is closed
+// Pairs with filter.DeferredFilter to filter then reduce a stream
+func ReduceChan(source <-chan interface{}, initialValue, reducer interface{}) (interface{}, error) {
+	if reducer == nil {
+		return nil, ErrReducerNil
+	}
+
+	rv := reflect.ValueOf(reducer)
+	if rv.Kind() != reflect.Func {
+		return nil, ErrReducerNotFunc
+	}
+
+	accV := reflect.ValueOf(initialValue)
+	i := 0
+	for entry := range source {
+		entryV := reflect.ValueOf(entry)
+		if !entryV.IsValid() {
+			// nil entry is sent as zero value of reducer's entry type
+			entryV = reflect.Zero(rv.Type().In(1))
+		}
+
+		// call reducer via reflection
+		reduceResults := rv.Call([]reflect.Value{
+			accV,               // send accumulator value
+			entryV,             // send current source entry
+			reflect.ValueOf(i), // send current loop index
+		})
+
+		accV = reduceResults[0]
+		i++
+	}
+
+	return accV.Interface(), nil
+}
@@ -117,3 +117,68 @@ func TestReduce(t *testing.T) {
 		})
 	}
 }
+
+func TestReduceChan(t *testing.T) {
+	source := make(chan interface{}, 3)
+	source <- 1
+	source <- 2
+	source <- 3
+	close(source)
+
+	var indexes []int
+	got, err := ReduceChan(source, 0, func(accumulator, entry, idx int) int {
+		indexes = append(indexes, idx)
+		return accumulator + entry
+	})
+	if err != nil {
+		t.Errorf("ReduceChan() error = %v, wantErr %v", err, false)
+		return
+	}
+	if got != 6 {
+		t.Errorf("ReduceChan() = %v, want %v", got, 6)
+	}
+	if !reflect.DeepEqual(indexes, []int{0, 1, 2}) {
+		t.Errorf("ReduceChan() indexes = %v, want %v", indexes, []int{0, 1, 2})
+	}
+
+	if _, err := ReduceChan(source, 0, nil); err != ErrReducerNil {
+		t.Errorf("ReduceChan() error = %v, want %v", err, ErrReducerNil)
+	}
+	if _, err := ReduceChan(source, 0, "something"); err != ErrReducerNotFunc {
+		t.Errorf("ReduceChan() error = %v, want %v", err, ErrReducerNotFunc)
+	}
+}
+
+func TestReduceUntil(t *testing.T) {
+	source := make([]int, 1000000)
+	source[42] = 1
+
+	visited := 0
+	anyMatch := func(accumulator bool, entry, idx int) (bool, bool) {
+		visited++
+		found := entry == 1
+		return found, found
+	}
+
+	got, err := ReduceUntil(source, false, anyMatch)
+	if err != nil {
+		t.Errorf("ReduceUntil() error = %v, wantErr %v", err, false)
+		return
+	}
+	if got != true {
+		t.Errorf("ReduceUntil() = %v, want %v", got, true)
+	}
+	if visited != 43 {
+		t.Errorf("ReduceUntil() visited = %v, want %v", visited, 43)
+	}
+
+	sumOfInt := func(accumulator, entry, idx int) int {
+		return accumulator + entry
+	}
+	if _, err := ReduceUntil(source, 0, sumOfInt); err != ErrReducerNotStop {
+		t.Errorf("ReduceUntil() error = %v, want %v", err, ErrReducerNotStop)
+	}
+	if _, err := ReduceUntil("something", false, anyMatch); err != ErrSourceNotArray {
+		t.Errorf("ReduceUntil() error = %v, want %v", err, ErrSourceNotArray)
+	}
+}
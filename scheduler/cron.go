@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField is the allowed range of a single cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+// cron fields, in the order they are written, excluding the optional leading second field
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+var secondField = cronField{"second", 0, 59}
+
+// cron is a parsed cron expression, each field is a bit set of allowed values
+type cron struct {
+	second, minute, hour, dom, month, dow uint64
+
+	// standard cron matches either day of month or day of week when both are restricted
+	domStar, dowStar bool
+}
+
+// parseCron parses a standard 5 fields cron expression: 'minute hour day-of-month month day-of-week'
+// An optional leading second field is also accepted, e.g: '* * * * * *' runs every second
+// Each field accepts '*', a value 'a', a range 'a-b', a step '*/n' or 'a-b/n', and a comma separated list of those
+func parseCron(expr string) (*cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 && len(fields) != 6 {
+		return nil, fmt.Errorf("%w: '%s' must have 5 or 6 fields", ErrCronInvalid, expr)
+	}
+
+	c := &cron{second: 1} // runs at second 0 unless specified
+	if len(fields) == 6 {
+		second, err := parseCronField(fields[0], secondField)
+		if err != nil {
+			return nil, err
+		}
+
+		c.second = second
+		fields = fields[1:]
+	}
+
+	targets := []*uint64{&c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	for i, field := range fields {
+		bits, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+
+		*targets[i] = bits
+	}
+
+	c.domStar = strings.HasPrefix(fields[2], "*")
+	c.dowStar = strings.HasPrefix(fields[4], "*")
+	return c, nil
+}
+
+func parseCronField(field string, rng cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%w: invalid step '%s' for %s", ErrCronInvalid, part, rng.name)
+			}
+
+			step = n
+			part = part[:idx]
+		}
+
+		lo, hi := rng.min, rng.max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("%w: invalid value '%s' for %s", ErrCronInvalid, part, rng.name)
+			}
+
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("%w: invalid value '%s' for %s", ErrCronInvalid, part, rng.name)
+				}
+			} else if step > 1 {
+				// 'a/n' means starting from a, every n
+				hi = rng.max
+			}
+		}
+
+		if lo < rng.min || hi > rng.max || lo > hi {
+			return 0, fmt.Errorf("%w: '%s' is out of range %d-%d for %s", ErrCronInvalid, part, rng.min, rng.max, rng.name)
+		}
+
+		for i := lo; i <= hi; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+func (c *cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// next get the earliest time matching the cron expression, strictly after t
+// Returns zero time if nothing matches within the next 5 years, e.g: '0 0 30 2 *'
+func (c *cron) next(t time.Time) time.Time {
+	t = t.Truncate(time.Second).Add(time.Second)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, t.Location())
+			continue
+		}
+		if c.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_parseCron(t *testing.T) {
+	at := func(value string) time.Time {
+		d, _ := time.Parse(time.RFC3339, value)
+		return d
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		after   time.Time
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "Every minute", expr: "* * * * *", after: at("2020-01-01T10:00:30Z"), want: at("2020-01-01T10:01:00Z")},
+		{name: "Every second", expr: "* * * * * *", after: at("2020-01-01T10:00:30Z"), want: at("2020-01-01T10:00:31Z")},
+		{name: "Every 15 minutes", expr: "*/15 * * * *", after: at("2020-01-01T10:16:00Z"), want: at("2020-01-01T10:30:00Z")},
+		{name: "Daily at 09:30", expr: "30 9 * * *", after: at("2020-01-01T10:00:00Z"), want: at("2020-01-02T09:30:00Z")},
+		{name: "Working hours", expr: "0 9-17 * * 1-5", after: at("2020-01-03T17:30:00Z"), want: at("2020-01-06T09:00:00Z")},
+		{name: "List of months", expr: "0 0 1 3,6 *", after: at("2020-03-01T00:00:00Z"), want: at("2020-06-01T00:00:00Z")},
+		{name: "Day of month or day of week", expr: "0 0 15 * 0", after: at("2020-01-01T00:00:00Z"), want: at("2020-01-05T00:00:00Z")},
+		{name: "Never runs", expr: "0 0 30 2 *", after: at("2020-01-01T00:00:00Z"), want: time.Time{}},
+		{name: "Too few fields", expr: "* * * *", wantErr: true},
+		{name: "Out of range", expr: "60 * * * *", wantErr: true},
+		{name: "Invalid step", expr: "*/0 * * * *", wantErr: true},
+		{name: "Not a number", expr: "a * * * *", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseCron(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCron() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrCronInvalid) {
+					t.Errorf("parseCron() error = %v, want ErrCronInvalid", err)
+				}
+				return
+			}
+			if got := c.next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_SchedulerCron(t *testing.T) {
+	var fired int32
+	sch := New(func(s *Scheduler, e *Event) {
+		atomic.AddInt32(&fired, 1)
+	})
+
+	if _, err := sch.ScheduleCron("every second", nil); err == nil {
+		t.Error("Invalid cron expression must return error")
+	}
+
+	start := time.Now()
+	if _, err := sch.ScheduleCron("* * * * * *", nil); err != nil {
+		t.Fatal("Cron event must be scheduled, got:", err)
+	}
+
+	// within 2.5 seconds, an every second cron must fire 2 or 3 times
+	time.Sleep(2500 * time.Millisecond)
+	pendings := sch.Stop()
+
+	count := atomic.LoadInt32(&fired)
+	if count < 2 || count > 3 {
+		t.Error("Cron event should fire 2-3 times within", time.Since(start), "instead fired", count, "times")
+	}
+	if len(pendings) != 1 {
+		t.Error("Cron event should be pending for its next run")
+	}
+}
@@ -1,20 +1,52 @@
 package scheduler
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
 	"time"
 )
 
+// LocalLayout is the datetime layout of an event in a named location, without offset
+const LocalLayout = "2006-01-02T15:04:05"
+
 // Attachment data associated with an event
-// Can be anything stored in bytes
+// Can be anything stored in bytes, or lazily loaded by Loader when the event fires
 type Attachment struct {
 	Name        string
 	ContentType string
 	Body        []byte
+	Loader      func() (io.ReadCloser, error) // used instead of Body when set
+}
+
+// NewLazyAttachment which body is only loaded when opened, to keep memory low for pending events
+func NewLazyAttachment(name, contentType string, loader func() (io.ReadCloser, error)) Attachment {
+	return Attachment{
+		Name:        name,
+		ContentType: contentType,
+		Loader:      loader,
+	}
+}
+
+// Open attachment body, by calling its Loader if any
+// Caller must close the returned reader
+func (att Attachment) Open() (io.ReadCloser, error) {
+	if att.Loader != nil {
+		return att.Loader()
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(att.Body)), nil
 }
 
 // Event which will run on scheduler
 type Event struct {
-	datetime    string // RFC3339 please
+	id          string         // stable identity, kept by every copy of the event
+	datetime    string         // RFC3339 please, or LocalLayout when location is set
+	location    *time.Location // named location of a local datetime, nil for RFC3339
+	interval    time.Duration  // recurring interval, zero if the event only runs once
+	cron        *cron          // recurring cron schedule, nil if the event only runs once
 	attachments []Attachment
 }
 
@@ -25,16 +57,67 @@ func NewEvent(d string, att []Attachment) *Event {
 	copy(cpy, att)
 
 	return &Event{
+		id:          newEventID(),
 		datetime:    d,
 		attachments: cpy,
 	}
 }
 
-// Date get event datetime, parsed into RFC3339 format
+// NewEventAfter create a new instance of immutable Event
+// which runs after d, counted from now. The absolute datetime is computed once, at construction
+func NewEventAfter(d time.Duration, att []Attachment) *Event {
+	return NewEvent(time.Now().Add(d).Format(time.RFC3339Nano), att)
+}
+
+// NewRecurringEvent create a new instance of immutable Event
+// which starts at start datetime, and recurs every interval until the scheduler is stopped
+func NewRecurringEvent(start string, interval time.Duration, att []Attachment) *Event {
+	e := NewEvent(start, att)
+	e.interval = interval
+	return e
+}
+
+// NewEventInLocation create a new instance of immutable Event
+// which runs at a local datetime (in LocalLayout) of a named location, e.g. Asia/Jakarta
+// The offset is resolved from the location at that datetime, so DST is taken into account
+func NewEventInLocation(localTime string, loc *time.Location, att []Attachment) *Event {
+	e := NewEvent(localTime, att)
+	e.location = loc
+	return e
+}
+
+// newEventID generates a random 128-bit hex id, unique across processes
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// ID get the stable identity of an event
+// Unlike the EventID returned by Schedule, it is generated once by NewEvent
+// and kept when the event is copied, e.g: rescheduled or re-scheduled from a Pending snapshot
+func (e *Event) ID() string {
+	return e.id
+}
+
+// Date get event datetime, parsed from RFC3339 format
+// or from LocalLayout in the event location
 func (e *Event) Date() (time.Time, error) {
+	if e.location != nil {
+		return time.ParseInLocation(LocalLayout, e.datetime, e.location)
+	}
+
 	return time.Parse(time.RFC3339, e.datetime)
 }
 
+// Location get the event location, nil if event datetime is in RFC3339
+func (e *Event) Location() *time.Location {
+	return e.location
+}
+
 // Attachments returns a copy of attachments slice
 // This is done to ensure immutability of event
 func (e *Event) Attachments() []Attachment {
@@ -42,3 +125,21 @@ func (e *Event) Attachments() []Attachment {
 	copy(cpy, e.attachments)
 	return cpy
 }
+
+// Interval get event recurring interval, zero if the event only runs once
+func (e *Event) Interval() time.Duration {
+	return e.interval
+}
+
+// next get the next run of a recurring event after the previous run
+// Returns zero time if event does not run anymore
+func (e *Event) next(previous time.Time) time.Time {
+	switch {
+	case e.interval > 0:
+		return previous.Add(e.interval)
+	case e.cron != nil:
+		return e.cron.next(previous)
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,205 @@
+package scheduler
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_NewEventInLocation(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip("Timezone database is not available:", err)
+	}
+
+	// Asia/Jakarta is UTC+7
+	ev := NewEventInLocation("2030-01-01T07:00:00", jakarta, nil)
+	date, err := ev.Date()
+	if err != nil {
+		t.Fatal("Local datetime must be parsed, got:", err)
+	}
+
+	want, _ := time.Parse(time.RFC3339, "2030-01-01T00:00:00Z")
+	if !date.Equal(want) {
+		t.Error("Event should fire at", want, "got:", date.UTC())
+	}
+	if ev.Location() != jakarta {
+		t.Error("Event should keep its location")
+	}
+
+	if _, err := NewEventInLocation("2030-01-01T07:00:00Z", jakarta, nil).Date(); err == nil {
+		t.Error("Local datetime with offset should not be parsed")
+	}
+}
+
+func Test_SchedulerInLocation(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip("Timezone database is not available:", err)
+	}
+
+	fired := make(chan *Event, 1)
+	sch := New(func(s *Scheduler, e *Event) {
+		fired <- e
+	})
+	defer sch.Stop()
+
+	// a minute ago in Jakarta local time
+	past := time.Now().In(jakarta).Add(-1 * time.Minute).Format(LocalLayout)
+	if _, err := sch.Schedule(NewEventInLocation(past, jakarta, nil)); err != ErrEventInPast {
+		t.Error("Past local datetime must return ErrEventInPast, got:", err)
+	}
+
+	one := time.Now().In(jakarta).Add(1 * time.Second).Format(LocalLayout)
+	ev := NewEventInLocation(one, jakarta, nil)
+	if _, err := sch.Schedule(ev); err != nil {
+		t.Fatal("Local datetime in the future must be scheduled, got:", err)
+	}
+
+	select {
+	case e := <-fired:
+		if e != ev {
+			t.Error("Event in location should be fired")
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("Event in location should have been fired within its local time")
+	}
+}
+
+func Test_AttachmentOpen(t *testing.T) {
+	att := Attachment{Name: "Here!", Body: []byte("eager")}
+	r, err := att.Open()
+	if err != nil {
+		t.Fatal("Eager attachment must be opened, got:", err)
+	}
+	defer r.Close()
+
+	if body, _ := ioutil.ReadAll(r); string(body) != "eager" {
+		t.Error("Eager attachment should read its Body, got:", string(body))
+	}
+}
+
+func Test_SchedulerLazyAttachment(t *testing.T) {
+	var loaded int32
+	loader := func() (io.ReadCloser, error) {
+		atomic.AddInt32(&loaded, 1)
+		return ioutil.NopCloser(strings.NewReader("lazy")), nil
+	}
+
+	bodies := make(chan string, 1)
+	sch := New(func(s *Scheduler, e *Event) {
+		r, err := e.Attachments()[0].Open()
+		if err != nil {
+			t.Error("Lazy attachment must be opened, got:", err)
+			return
+		}
+		defer r.Close()
+
+		body, _ := ioutil.ReadAll(r)
+		bodies <- string(body)
+	})
+	defer sch.Stop()
+
+	att := []Attachment{NewLazyAttachment("Here!", "text/plain", loader)}
+	ev := NewEvent(time.Now().Add(1*time.Second).Format(time.RFC3339), att)
+	sch.Schedule(ev)
+
+	// attachment is mutated after scheduled, event must keep its own copy
+	att[0].Name = "THERE!"
+	if atomic.LoadInt32(&loaded) != 0 {
+		t.Error("Loader must not be called at schedule time")
+	}
+
+	select {
+	case body := <-bodies:
+		if body != "lazy" {
+			t.Error("Lazy attachment should read from its loader, got:", body)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Event should have been fired")
+	}
+
+	if atomic.LoadInt32(&loaded) != 1 {
+		t.Error("Loader should be called once at fire time, got:", atomic.LoadInt32(&loaded))
+	}
+	if ev.Attachments()[0].Name != "Here!" {
+		t.Error("Attachment of scheduled event must be immutable")
+	}
+}
+
+func Test_EventID(t *testing.T) {
+	ids := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		id := NewEvent("", nil).ID()
+		if id == "" || ids[id] {
+			t.Fatal("Event ID should be unique, got:", id)
+		}
+		ids[id] = true
+	}
+
+	far := time.Now().Add(1 * time.Hour)
+	sch := New(func(s *Scheduler, e *Event) {})
+	var want []string
+	for i := 0; i < 3; i++ {
+		ev := NewEvent(far.Add(time.Duration(i)*time.Second).Format(time.RFC3339), nil)
+		sch.Schedule(ev)
+		want = append(want, ev.ID())
+	}
+
+	// rescheduled event is a copy, but keeps its identity
+	sch.Reschedule(1, far.Add(1*time.Minute).Format(time.RFC3339))
+
+	// snapshot pending events, and restore them into another scheduler
+	snapshot := sch.Stop()
+	restored := New(func(s *Scheduler, e *Event) {})
+	for _, ev := range snapshot {
+		restored.Schedule(ev)
+	}
+
+	pendings := restored.Stop()
+	if len(pendings) != len(want) {
+		t.Fatal("All events should be restored, got:", len(pendings))
+	}
+	for i, ev := range pendings {
+		if ev.ID() != want[i] {
+			t.Error("Event ID should be stable through snapshot, want:", want[i], "got:", ev.ID())
+		}
+	}
+}
+
+func Test_NewEventAfter(t *testing.T) {
+	before := time.Now()
+	ev := NewEventAfter(200*time.Millisecond, nil)
+
+	date, err := ev.Date()
+	if err != nil {
+		t.Fatal("Relative event date must be valid, got:", err)
+	}
+	if at := before.Add(200 * time.Millisecond); date.Before(at) || date.After(at.Add(100*time.Millisecond)) {
+		t.Error("Relative event should run 200ms from construction, got:", date.Sub(before))
+	}
+
+	fired := make(chan *Event, 1)
+	sch := New(func(s *Scheduler, e *Event) {
+		fired <- e
+	})
+	if _, err := sch.Schedule(ev); err != nil {
+		t.Fatal("Relative event must be scheduled, got:", err)
+	}
+
+	select {
+	case e := <-fired:
+		if e != ev {
+			t.Error("Relative event should be fired")
+		}
+		if elapsed := time.Since(before); elapsed < 200*time.Millisecond {
+			t.Error("Relative event should not fire before 200ms, fired after:", elapsed)
+		}
+	case <-time.After(1 * time.Second):
+		t.Error("Relative event should have been fired")
+	}
+	sch.Stop()
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// scheduled is a live event which have not been fired or cancelled
+type scheduled struct {
+	id    EventID
+	event *Event
+	at    time.Time // next fire time, jittered
+	base  time.Time // next fire time without jitter, from which recurring event is re-armed
+	index int       // index in timerHeap, maintained by container/heap
+}
+
+// timerHeap is a min-heap of scheduled events, ordered by their next fire time
+// implements container/heap.Interface
+type timerHeap []*scheduled
+
+func (h timerHeap) Len() int { return len(h) }
+
+func (h timerHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].id < h[j].id
+	}
+
+	return h[i].at.Before(h[j].at)
+}
+
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x interface{}) {
+	entry := x.(*scheduled)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *timerHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	entry.index = -1
+	*h = old[:n-1]
+	return entry
+}
@@ -0,0 +1,34 @@
+package scheduler
+
+import (
+	"sync/atomic"
+)
+
+// SchedulerMetrics is a snapshot of scheduler counters, e.g: to be exported as gauges
+type SchedulerMetrics struct {
+	Scheduled uint64 // events accepted by Schedule
+	Fired     uint64 // events fired, a recurring event counts once for each run
+	Cancelled uint64 // events cancelled before they are fired
+	Pending   int    // events which have not been fired or cancelled, zero once stopped
+}
+
+// counters of a scheduler, updated atomically
+type counters struct {
+	scheduled uint64
+	fired     uint64
+	cancelled uint64
+}
+
+// Metrics returns a snapshot of scheduler counters
+func (s *Scheduler) Metrics() SchedulerMetrics {
+	s.mux.Lock()
+	pending := len(s.scheduled)
+	s.mux.Unlock()
+
+	return SchedulerMetrics{
+		Scheduled: atomic.LoadUint64(&s.counters.scheduled),
+		Fired:     atomic.LoadUint64(&s.counters.fired),
+		Cancelled: atomic.LoadUint64(&s.counters.cancelled),
+		Pending:   pending,
+	}
+}
@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// Option configures a scheduler on New
+type Option func(*Scheduler)
+
+// ErrorHandler is called when a delegate panics, with the recovered value
+type ErrorHandler func(e *Event, recovered interface{})
+
+// WithErrorHandler sets a callback which is called when a delegate panics
+// Without an error handler, the panic is recovered and discarded
+func WithErrorHandler(onError ErrorHandler) Option {
+	return func(s *Scheduler) {
+		s.onError = onError
+	}
+}
+
+// WithJitter offsets the fire time of each event randomly by up to ±jitter, so events scheduled
+// at the same time do not stampede downstream. An offset never moves an event before now
+// Recurring event is re-armed from its schedule, so jitter does not accumulate over runs
+func WithJitter(jitter time.Duration) Option {
+	return func(s *Scheduler) {
+		s.jitter = jitter
+	}
+}
+
+// WithWorkers dispatch fired events on a bounded pool of n workers
+// Without workers, each fired event is dispatched on its own go routine
+func WithWorkers(n int) Option {
+	return func(s *Scheduler) {
+		s.workers = n
+	}
+}
@@ -1,81 +1,337 @@
 package scheduler
 
 import (
+	"container/heap"
 	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Scheduler error collection
 var (
-	ErrEventInPast = errors.New("Event datetime is in the past")
-	ErrTimeInvalid = errors.New("Datetime format is not in RFC3339")
+	ErrEventInPast      = errors.New("Event datetime is in the past")
+	ErrTimeInvalid      = errors.New("Datetime format is not in RFC3339")
+	ErrEventNotFound    = errors.New("Event is not scheduled, or have been fired or cancelled")
+	ErrCronInvalid      = errors.New("Cron expression is invalid")
+	ErrSchedulerStopped = errors.New("Scheduler have been stopped")
 )
 
 // EventHandler delegates
 type EventHandler func(*Scheduler, *Event)
 
-// Scheduler ...
+// EventID is an opaque identifier of a scheduled event
+type EventID uint64
+
+// Scheduler runs a single go routine which sleeps until the nearest event,
+// instead of a go routine for each scheduled event
 type Scheduler struct {
+	counters counters // first, so its 64-bit words are aligned for atomic access
 	delegate EventHandler
-	stop     chan struct{}
-	pendings chan *Event
-	wg       *sync.WaitGroup
+	onError  ErrorHandler
+	stop     chan struct{}   // closed when scheduler is stopped
+	wake     chan struct{}   // signals the timer loop that the nearest event might have changed
+	done     chan struct{}   // closed when the timer loop exits
+	wg       *sync.WaitGroup // running delegates
+	workers  int             // size of worker pool, zero means a go routine for each fired event
+	jitter   time.Duration   // maximum random offset of each fire time, zero means no jitter
+	jobs     chan *Event     // fired events, waiting to be dispatched by the worker pool
+
+	// scheduled events, ordered in a timer heap and indexed by its id
+	mux       sync.Mutex
+	seq       EventID
+	queue     timerHeap
+	scheduled map[EventID]*scheduled
+	stopped   bool
 }
 
 // New instance of scheduler
-func New(d EventHandler) *Scheduler {
-	return &Scheduler{
+func New(d EventHandler, opts ...Option) *Scheduler {
+	s := &Scheduler{
 		delegate: d,
 		// initialize stop channel
 		stop: make(chan struct{}),
-		// initialize buffered event channel
-		pendings: make(chan *Event, 3),
-		wg:       &sync.WaitGroup{},
+		// initialize buffered wake channel, 1 signal is enough to re-evaluate the nearest event
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		wg:        &sync.WaitGroup{},
+		scheduled: map[EventID]*scheduled{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.workers > 0 {
+		s.jobs = make(chan *Event, s.workers)
+		for i := 0; i < s.workers; i++ {
+			go s.work()
+		}
 	}
+
+	go s.run()
+	return s
 }
 
 // Schedule an event
-func (s *Scheduler) Schedule(e *Event) error {
+// Returns an EventID which can be used to cancel the event
+func (s *Scheduler) Schedule(e *Event) (EventID, error) {
 	date, err := e.Date()
 	if err != nil {
-		return ErrTimeInvalid
+		return 0, ErrTimeInvalid
 	}
 
 	now := time.Now()
-	if date.Unix() <= now.Unix() {
-		return ErrEventInPast
+	if !date.After(now) {
+		return 0, ErrEventInPast
+	}
+
+	s.mux.Lock()
+	if s.stopped {
+		s.mux.Unlock()
+		return 0, ErrSchedulerStopped
+	}
+
+	s.seq++
+	entry := &scheduled{id: s.seq, event: e, at: s.jittered(date), base: date}
+	s.scheduled[entry.id] = entry
+	heap.Push(&s.queue, entry)
+	s.mux.Unlock()
+
+	atomic.AddUint64(&s.counters.scheduled, 1)
+	s.notify()
+	return entry.id, nil
+}
+
+// ScheduleCron schedule a recurring event which runs at every time matching a cron expression
+// See parseCron for the supported syntax
+func (s *Scheduler) ScheduleCron(expr string, att []Attachment) (EventID, error) {
+	c, err := parseCron(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	first := c.next(time.Now())
+	if first.IsZero() {
+		return 0, fmt.Errorf("%w: '%s' never runs", ErrCronInvalid, expr)
+	}
+
+	e := NewEvent(first.Format(time.RFC3339), att)
+	e.cron = c
+	return s.Schedule(e)
+}
+
+// jittered offsets t randomly by up to ±jitter, but never before now
+func (s *Scheduler) jittered(t time.Time) time.Time {
+	if s.jitter <= 0 {
+		return t
+	}
+
+	offset := time.Duration(rand.Int63n(int64(2*s.jitter)+1)) - s.jitter
+	at := t.Add(offset)
+	if now := time.Now(); at.Before(now) {
+		return now
+	}
+
+	return at
+}
+
+// notify the timer loop without blocking, in case it's busy a signal is already waiting
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
 	}
+}
+
+// run is the timer loop, sleeping until the nearest event or until the heap is changed
+func (s *Scheduler) run() {
+	defer close(s.done)
 
-	s.wg.Add(1)
-	// fire a go routine
-	go func(e *Event) {
-		now := time.Now()
-		target, _ := e.Date()
-		waitDuration := target.Sub(now)
+	for {
+		var timer *time.Timer
+		var fire <-chan time.Time
+
+		s.mux.Lock()
+		if len(s.queue) > 0 {
+			timer = time.NewTimer(time.Until(s.queue[0].at))
+			fire = timer.C
+		}
+		s.mux.Unlock()
 
-		defer s.wg.Done()
 		select {
-		case <-time.After(waitDuration):
-			s.delegate(s, e)
+		case <-fire:
+			s.fire(time.Now())
+		case <-s.wake:
 		case <-s.stop:
-			s.pendings <- e
 		}
-	}(e)
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+	}
+}
+
+// fire all events due at now, and re-arm the recurring ones
+func (s *Scheduler) fire(now time.Time) {
+	var due []*Event
+
+	s.mux.Lock()
+	for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+		entry := s.queue[0]
+		due = append(due, entry.event)
+
+		// re-arm the timer for recurring event
+		next := entry.event.next(entry.base)
+		if next.IsZero() {
+			heap.Pop(&s.queue)
+			delete(s.scheduled, entry.id)
+			continue
+		}
+
+		entry.at = s.jittered(next)
+		entry.base = next
+		heap.Fix(&s.queue, entry.index)
+	}
+	s.mux.Unlock()
+
+	atomic.AddUint64(&s.counters.fired, uint64(len(due)))
+	for _, e := range due {
+		s.wg.Add(1)
+		if s.jobs != nil {
+			s.jobs <- e
+			continue
+		}
+
+		go s.dispatch(e)
+	}
+}
+
+// work dispatch fired events from the worker pool, until the scheduler is stopped
+func (s *Scheduler) work() {
+	for e := range s.jobs {
+		s.dispatch(e)
+	}
+}
+
+// dispatch an event to the delegate, recovering from panic so a faulty delegate will not hang Stop
+func (s *Scheduler) dispatch(e *Event) {
+	defer s.wg.Done()
+	defer func() {
+		if r := recover(); r != nil && s.onError != nil {
+			s.onError(e, r)
+		}
+	}()
+
+	s.delegate(s, e)
+}
+
+// Cancel a scheduled event without affecting the others
+// A cancelled event will not be fired, and will not be reported as pending
+func (s *Scheduler) Cancel(id EventID) error {
+	s.mux.Lock()
+	entry, exists := s.scheduled[id]
+	if !exists {
+		s.mux.Unlock()
+		return ErrEventNotFound
+	}
+
+	delete(s.scheduled, id)
+	heap.Remove(&s.queue, entry.index)
+	s.mux.Unlock()
+
+	atomic.AddUint64(&s.counters.cancelled, 1)
+	s.notify()
 	return nil
 }
 
+// Reschedule move a scheduled event to a new datetime, keeping its EventID
+// As event is immutable, the scheduled event is replaced by a copy with the new datetime
+func (s *Scheduler) Reschedule(id EventID, newDatetime string) error {
+	date, err := time.Parse(time.RFC3339, newDatetime)
+	if err != nil {
+		return ErrTimeInvalid
+	}
+
+	now := time.Now()
+	if !date.After(now) {
+		return ErrEventInPast
+	}
+
+	s.mux.Lock()
+	entry, exists := s.scheduled[id]
+	if !exists {
+		s.mux.Unlock()
+		return ErrEventNotFound
+	}
+
+	cpy := *entry.event
+	cpy.datetime = newDatetime
+	cpy.location = nil // new datetime is in RFC3339
+	entry.event = &cpy
+	entry.at = s.jittered(date)
+	entry.base = date
+	heap.Fix(&s.queue, entry.index)
+	s.mux.Unlock()
+
+	s.notify()
+	return nil
+}
+
+// Pending returns a snapshot of events which have not been fired or cancelled, ordered by schedule
+// A recurring event is always pending for its next run
+func (s *Scheduler) Pending() []*Event {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	ids := make([]EventID, 0, len(s.scheduled))
+	for id := range s.scheduled {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	events := make([]*Event, len(ids))
+	for i, id := range ids {
+		events[i] = s.scheduled[id].event
+	}
+
+	return events
+}
+
 // Stop all running scheduler and report all pending events
+// Waits for all running delegates to complete
 func (s *Scheduler) Stop() (events []*Event) {
-	close(s.stop)
-	go func() {
-		s.wg.Wait()
-		close(s.pendings)
-	}()
+	s.mux.Lock()
+	first := !s.stopped
+	if first {
+		s.stopped = true
+		close(s.stop)
+	}
+	s.mux.Unlock()
+
+	// wait for timer loop to exit, so no more event is fired
+	<-s.done
+
+	events = s.Pending()
+	s.mux.Lock()
+	s.queue = nil
+	s.scheduled = map[EventID]*scheduled{}
+	s.mux.Unlock()
 
-	for e := range s.pendings {
-		events = append(events, e)
+	// no more event is fired, so worker pool can exit after dispatching the fired ones
+	if first && s.jobs != nil {
+		close(s.jobs)
 	}
 
+	s.wg.Wait()
 	return events
 }
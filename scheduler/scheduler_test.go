@@ -1,6 +1,9 @@
 package scheduler
 
 import (
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -44,3 +47,349 @@ func Test_Scheduler(t *testing.T) {
 		}
 	}
 }
+
+func Test_SchedulerRecurring(t *testing.T) {
+	var fired int32
+	sch := New(func(s *Scheduler, e *Event) {
+		atomic.AddInt32(&fired, 1)
+	})
+
+	// start at the next whole second, as RFC3339 is only precise to a second
+	start := time.Now().Truncate(time.Second).Add(1 * time.Second)
+	interval := 100 * time.Millisecond
+	ev := NewRecurringEvent(start.Format(time.RFC3339), interval, nil)
+	if _, err := sch.Schedule(ev); err != nil {
+		t.Fatal("Recurring event must be scheduled, got:", err)
+	}
+
+	// wait for 5 intervals (plus a half to avoid racing the 6th run)
+	n := 5
+	time.Sleep(time.Until(start.Add(time.Duration(n)*interval + interval/2)))
+
+	pendings := sch.Stop()
+	count := int(atomic.LoadInt32(&fired))
+	if count < n || count > n+1 {
+		t.Error("Recurring event should fire around", n+1, "times, instead fired", count, "times")
+	}
+	if len(pendings) != 1 || pendings[0] != ev {
+		t.Error("Recurring event should be pending for its next run")
+	}
+
+	// no more firing after scheduler is stopped
+	time.Sleep(2 * interval)
+	if int(atomic.LoadInt32(&fired)) != count {
+		t.Error("Recurring event must not fire after scheduler is stopped")
+	}
+}
+
+func Test_SchedulerCancel(t *testing.T) {
+	fired := make(chan *Event, 3)
+	sch := New(func(s *Scheduler, e *Event) {
+		fired <- e
+	})
+
+	one := time.Now().Add(1 * time.Second).Format(time.RFC3339)
+	two := time.Now().Add(5 * time.Second).Format(time.RFC3339)
+
+	ev1 := NewEvent(one, nil)
+	id1, _ := sch.Schedule(ev1)
+	ev2 := NewEvent(one, nil)
+	sch.Schedule(ev2)
+	ev3 := NewEvent(two, nil)
+	id3, _ := sch.Schedule(ev3)
+
+	if err := sch.Cancel(id1); err != nil {
+		t.Error("Cancelling a scheduled event must not return error, got:", err)
+	}
+	if err := sch.Cancel(id1); err != ErrEventNotFound {
+		t.Error("Cancelling a cancelled event must return ErrEventNotFound, got:", err)
+	}
+	if err := sch.Cancel(id3); err != nil {
+		t.Error("Cancelling a scheduled event must not return error, got:", err)
+	}
+
+	// only ev2 must be fired
+	select {
+	case e := <-fired:
+		if e != ev2 {
+			t.Error("Only ev2 should be fired")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("ev2 should be fired")
+	}
+
+	pendings := sch.Stop()
+	if len(fired) != 0 {
+		t.Error("Cancelled event must not be fired")
+	}
+	if len(pendings) != 0 {
+		t.Error("Cancelled event must not be reported as pending, got:", len(pendings))
+	}
+}
+
+func Test_SchedulerPending(t *testing.T) {
+	sch := New(func(s *Scheduler, e *Event) {})
+
+	one := time.Now().Add(1 * time.Second).Format(time.RFC3339)
+	far := time.Now().Add(1 * time.Hour).Format(time.RFC3339)
+
+	ev1 := NewEvent(one, nil)
+	sch.Schedule(ev1)
+	ev2 := NewEvent(far, nil)
+	id2, _ := sch.Schedule(ev2)
+	ev3 := NewEvent(far, nil)
+	sch.Schedule(ev3)
+
+	if pendings := sch.Pending(); len(pendings) != 3 {
+		t.Error("All 3 events should be pending, got:", len(pendings))
+	}
+
+	// after ev1 fired and ev2 cancelled, only ev3 is pending
+	sch.Cancel(id2)
+	time.Sleep(1500 * time.Millisecond)
+	if pendings := sch.Pending(); len(pendings) != 1 || pendings[0] != ev3 {
+		t.Error("Only ev3 should be pending, got:", len(pendings))
+	}
+
+	// pending does not stop the scheduler
+	pendings := sch.Stop()
+	if len(pendings) != 1 || pendings[0] != ev3 {
+		t.Error("Only ev3 should be pending after stop, got:", len(pendings))
+	}
+}
+
+func Test_SchedulerManyEvents(t *testing.T) {
+	fired := make(chan *Event, 1)
+	sch := New(func(s *Scheduler, e *Event) {
+		fired <- e
+	})
+
+	// plenty of far away events must not spawn a go routine each
+	before := runtime.NumGoroutine()
+	far := time.Now().Add(1 * time.Hour)
+	for i := 0; i < 10000; i++ {
+		ev := NewEvent(far.Add(time.Duration(i)*time.Second).Format(time.RFC3339), nil)
+		if _, err := sch.Schedule(ev); err != nil {
+			t.Fatal("Event must be scheduled, got:", err)
+		}
+	}
+	if after := runtime.NumGoroutine(); after-before > 10 {
+		t.Error("Scheduling events should not spawn go routines, got:", after-before)
+	}
+
+	// an earlier event arriving later must be fired first
+	near := NewEvent(time.Now().Add(1*time.Second).Format(time.RFC3339), nil)
+	sch.Schedule(near)
+	select {
+	case e := <-fired:
+		if e != near {
+			t.Error("The nearest event should be fired first")
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("The nearest event should have been fired")
+	}
+
+	pendings := sch.Stop()
+	if len(pendings) != 10000 {
+		t.Error("All unfired events should be pending, got:", len(pendings))
+	}
+}
+
+func Test_SchedulerReschedule(t *testing.T) {
+	fired := make(chan *Event, 1)
+	sch := New(func(s *Scheduler, e *Event) {
+		fired <- e
+	})
+
+	far := time.Now().Add(1 * time.Hour).Format(time.RFC3339)
+	one := time.Now().Add(1 * time.Second).Format(time.RFC3339)
+	past := time.Now().Add(-1 * time.Second).Format(time.RFC3339)
+
+	ev := NewEvent(far, []Attachment{{Name: "Here!"}})
+	id, _ := sch.Schedule(ev)
+
+	if err := sch.Reschedule(id, past); err != ErrEventInPast {
+		t.Error("Rescheduling into the past must return ErrEventInPast, got:", err)
+	}
+	if err := sch.Reschedule(id, "tomorrow"); err != ErrTimeInvalid {
+		t.Error("Rescheduling into invalid datetime must return ErrTimeInvalid, got:", err)
+	}
+	if err := sch.Reschedule(id+1, one); err != ErrEventNotFound {
+		t.Error("Rescheduling unknown event must return ErrEventNotFound, got:", err)
+	}
+	if err := sch.Reschedule(id, one); err != nil {
+		t.Error("Rescheduling into the future must not return error, got:", err)
+	}
+
+	select {
+	case e := <-fired:
+		if e.datetime != one {
+			t.Error("Rescheduled event should fire at", one, "got:", e.datetime)
+		}
+		if e.Attachments()[0].Name != "Here!" {
+			t.Error("Rescheduled event should keep its attachments")
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("Rescheduled event should have been fired")
+	}
+
+	if ev.datetime != far {
+		t.Error("Original event must not be mutated")
+	}
+	if pendings := sch.Stop(); len(pendings) != 0 {
+		t.Error("Rescheduled event must not be pending after fired, got:", len(pendings))
+	}
+}
+
+func Test_SchedulerPanic(t *testing.T) {
+	recovered := make(chan interface{}, 1)
+	sch := New(func(s *Scheduler, e *Event) {
+		panic("boom")
+	}, WithErrorHandler(func(e *Event, r interface{}) {
+		recovered <- r
+	}))
+
+	ev := NewEvent(time.Now().Add(1*time.Second).Format(time.RFC3339), nil)
+	sch.Schedule(ev)
+
+	select {
+	case r := <-recovered:
+		if r != "boom" {
+			t.Error("Error handler should receive the recovered value, got:", r)
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("Error handler should have been called")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		sch.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(1 * time.Second):
+		t.Error("Stop should return after a delegate panicked")
+	}
+}
+
+func Test_SchedulerWorkers(t *testing.T) {
+	var running, peak, fired int32
+	sch := New(func(s *Scheduler, e *Event) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		time.Sleep(50 * time.Millisecond) // slow delegate
+		atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&fired, 1)
+	}, WithWorkers(5))
+
+	// 50 events fired at the same time
+	at := time.Now().Truncate(time.Second).Add(1 * time.Second).Format(time.RFC3339)
+	for i := 0; i < 50; i++ {
+		sch.Schedule(NewEvent(at, nil))
+	}
+	far := NewEvent(time.Now().Add(1*time.Hour).Format(time.RFC3339), nil)
+	sch.Schedule(far)
+
+	time.Sleep(1100 * time.Millisecond)
+	pendings := sch.Stop()
+
+	if fired := atomic.LoadInt32(&fired); fired != 50 {
+		t.Error("All fired events should be dispatched before Stop returns, got:", fired)
+	}
+	if peak := atomic.LoadInt32(&peak); peak > 5 {
+		t.Error("At most 5 delegates should run at the same time, got:", peak)
+	}
+	if len(pendings) != 1 || pendings[0] != far {
+		t.Error("Only the far event should be pending, got:", len(pendings))
+	}
+	sch.Stop() // stopping twice must not panic
+}
+
+func Test_SchedulerMetrics(t *testing.T) {
+	fired := make(chan *Event, 2)
+	sch := New(func(s *Scheduler, e *Event) {
+		fired <- e
+	})
+
+	one := time.Now().Add(1 * time.Second).Format(time.RFC3339)
+	far := time.Now().Add(1 * time.Hour).Format(time.RFC3339)
+
+	sch.Schedule(NewEvent(one, nil))
+	sch.Schedule(NewEvent(one, nil))
+	id, _ := sch.Schedule(NewEvent(far, nil))
+	sch.Schedule(NewEvent(far, nil))
+	sch.Cancel(id)
+
+	if m := sch.Metrics(); m.Scheduled != 4 || m.Fired != 0 || m.Cancelled != 1 || m.Pending != 3 {
+		t.Error("Metrics should count 4 scheduled, 1 cancelled, and 3 pending events, got:", m)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-fired:
+		case <-time.After(3 * time.Second):
+			t.Fatal("Events should have been fired")
+		}
+	}
+
+	if m := sch.Metrics(); m.Scheduled != 4 || m.Fired != 2 || m.Cancelled != 1 || m.Pending != 1 {
+		t.Error("Metrics should count 2 fired, and 1 pending event, got:", m)
+	}
+
+	sch.Stop()
+	if m := sch.Metrics(); m.Fired != 2 || m.Pending != 0 {
+		t.Error("Stopped scheduler should have no pending event, got:", m)
+	}
+}
+
+func Test_SchedulerJitter(t *testing.T) {
+	mux := sync.Mutex{}
+	var fired []time.Time
+	sch := New(func(s *Scheduler, e *Event) {
+		mux.Lock()
+		fired = append(fired, time.Now())
+		mux.Unlock()
+	}, WithJitter(200*time.Millisecond))
+
+	// 20 events at the same instant, the nearest jitter can not move them before now
+	now := time.Now()
+	at := now.Add(100 * time.Millisecond).Format(time.RFC3339Nano)
+	for i := 0; i < 20; i++ {
+		if _, err := sch.Schedule(NewEvent(at, nil)); err != nil {
+			t.Fatal("Event must be scheduled, got:", err)
+		}
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	sch.Stop()
+
+	mux.Lock()
+	defer mux.Unlock()
+	if len(fired) != 20 {
+		t.Fatal("All jittered events should be fired, got:", len(fired))
+	}
+
+	first, last := fired[0], fired[0]
+	for _, f := range fired {
+		if f.Before(now) {
+			t.Error("Jitter must not fire an event before it is scheduled")
+		}
+		if f.Before(first) {
+			first = f
+		}
+		if f.After(last) {
+			last = f
+		}
+	}
+	if spread := last.Sub(first); spread < 50*time.Millisecond {
+		t.Error("Fire times should be spread by jitter, got:", spread)
+	}
+}